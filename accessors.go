@@ -0,0 +1,125 @@
+package typeregistry
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateAccessors writes the source of a Go package named pkg with a typed
+// New and Unmarshal function for each registered type, such as
+// NewOrderPlaced() orders.OrderPlaced, so that application code can use the
+// registry with compile time type checks. Pointers, slices and arrays add Ptr,
+// Slice and Array to the name, such as NewOrderPlacedPtr() *orders.OrderPlaced.
+// The generated package has a Registry variable that must be set before the
+// functions are called.
+// Generate it from a complete registry, such as one frozen by a Lifecycle.
+// Types that cannot be referred to from another package, such as unexported
+// or generic types, are skipped. It returns an error if two packages with the
+// same name are used, or two types cannot be given distinct function names.
+func (r TypeRegistry) GenerateAccessors(w io.Writer, pkg string) error {
+	names := r.Names()
+
+	var (
+		imports = make(map[string]string)
+		idents  = make(map[string]bool)
+		body    bytes.Buffer
+	)
+	for _, name := range names {
+		t := r[name]
+		base, shape, ok := accessorBase(t)
+		if !ok {
+			continue
+		}
+		if path := base.PkgPath(); path != "" {
+			alias := strings.SplitN(base.String(), ".", 2)[0]
+			if other, ok := imports[alias]; ok && other != path {
+				return fmt.Errorf("typeregistry cannot import both %s and %s as %s", other, path, alias)
+			}
+			imports[alias] = path
+		}
+		ident := exported(base.Name()) + shape
+		if idents[ident] {
+			ident = exported(strings.SplitN(base.String(), ".", 2)[0]) + ident
+		}
+		if idents[ident] {
+			return fmt.Errorf("typeregistry cannot name the accessors for %s", name)
+		}
+		idents[ident] = true
+		fmt.Fprintf(&body, accessorTemplate, ident, name, t)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by typeregistry. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	fmt.Fprintf(&buf, "\t%q\n", "github.com/rcarver/typeregistry")
+	aliases := make([]string, 0, len(imports))
+	for alias := range imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		fmt.Fprintf(&buf, "\t%s %q\n", alias, imports[alias])
+	}
+	buf.WriteString(")\n\n// Registry is used by the accessors. Set it before calling them.\nvar Registry typeregistry.Registry\n")
+	buf.Write(body.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+const accessorTemplate = `
+// New%s instantiates %q.
+func New%[1]s() %[3]s {
+	return Registry.New(%[2]q).(%[3]s)
+}
+
+// Unmarshal%[1]s decodes %[2]q.
+func Unmarshal%[1]s(data []byte, setup typeregistry.SetupFunc) (%[3]s, error) {
+	o, err := Registry.Unmarshal(%[2]q, data, setup)
+	v, _ := o.(%[3]s)
+	return v, err
+}
+`
+
+// accessorBase returns the named type that t is made from, and the shape
+// that t gives it, such as "PtrSlice" for a []*T, or false if t cannot be
+// referred to from another package.
+func accessorBase(t reflect.Type) (reflect.Type, string, bool) {
+	var shape string
+	for t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Ptr:
+			shape = "Ptr" + shape
+		case reflect.Slice:
+			shape = "Slice" + shape
+		case reflect.Array:
+			shape = "Array" + shape
+		default:
+			return nil, "", false
+		}
+		t = t.Elem()
+	}
+	name := t.Name()
+	if strings.Contains(name, "[") || t.PkgPath() == "main" {
+		return nil, "", false
+	}
+	if t.PkgPath() != "" && !unicode.IsUpper([]rune(name)[0]) {
+		return nil, "", false
+	}
+	return t, shape, true
+}
+
+// exported returns name with its first letter in upper case.
+func exported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,86 @@
+package typeregistry
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypeRegistry_GenerateAccessors(t *testing.T) {
+	r := New()
+	r.Add(time.Time{})
+	r.Add(&url.URL{})
+	r.Add([]time.Duration{})
+	r.Add(&nameType{})
+
+	var buf bytes.Buffer
+	if err := r.GenerateAccessors(&buf, "regtypes"); err != nil {
+		t.Fatalf("GenerateAccessors() wants no error, got: %s", err)
+	}
+	want := `// Code generated by typeregistry. DO NOT EDIT.
+
+package regtypes
+
+import (
+	"github.com/rcarver/typeregistry"
+	url "net/url"
+	time "time"
+)
+
+// Registry is used by the accessors. Set it before calling them.
+var Registry typeregistry.Registry
+
+// NewURLPtr instantiates "*url.URL".
+func NewURLPtr() *url.URL {
+	return Registry.New("*url.URL").(*url.URL)
+}
+
+// UnmarshalURLPtr decodes "*url.URL".
+func UnmarshalURLPtr(data []byte, setup typeregistry.SetupFunc) (*url.URL, error) {
+	o, err := Registry.Unmarshal("*url.URL", data, setup)
+	v, _ := o.(*url.URL)
+	return v, err
+}
+
+// NewDurationSlice instantiates "[]time.Duration".
+func NewDurationSlice() []time.Duration {
+	return Registry.New("[]time.Duration").([]time.Duration)
+}
+
+// UnmarshalDurationSlice decodes "[]time.Duration".
+func UnmarshalDurationSlice(data []byte, setup typeregistry.SetupFunc) ([]time.Duration, error) {
+	o, err := Registry.Unmarshal("[]time.Duration", data, setup)
+	v, _ := o.([]time.Duration)
+	return v, err
+}
+
+// NewTime instantiates "time.Time".
+func NewTime() time.Time {
+	return Registry.New("time.Time").(time.Time)
+}
+
+// UnmarshalTime decodes "time.Time".
+func UnmarshalTime(data []byte, setup typeregistry.SetupFunc) (time.Time, error) {
+	o, err := Registry.Unmarshal("time.Time", data, setup)
+	v, _ := o.(time.Time)
+	return v, err
+}
+`
+	if buf.String() != want {
+		t.Errorf("GenerateAccessors() got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	r.Add(&time.Time{})
+	r.Add([]time.Time{})
+	buf.Reset()
+	if err := r.GenerateAccessors(&buf, "regtypes"); err != nil {
+		t.Fatalf("GenerateAccessors() of one type in several shapes wants no error, got: %s", err)
+	}
+	for _, want := range []string{"func NewTime() time.Time", "func NewTimePtr() *time.Time", "func NewTimeSlice() []time.Time"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("GenerateAccessors() got\n%s\nwant %s", buf.String(), want)
+		}
+	}
+}
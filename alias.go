@@ -0,0 +1,104 @@
+package typeregistry
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AliasRegistry is a Registry that also knows types by old names, such as the
+// name of a type before it was moved to another package, so that data stored
+// under an old name can still be instantiated and unmarshaled. Aliases are
+// kept apart from the registry, so only registered names are listed by Names,
+// and values are still marshaled under their registered names.
+type AliasRegistry struct {
+	TypeRegistry TypeRegistry
+
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// WithAliases wraps a registry so that old names can be added with Alias.
+func WithAliases(r TypeRegistry) *AliasRegistry {
+	return &AliasRegistry{TypeRegistry: r, aliases: make(map[string]string)}
+}
+
+// Alias makes old another name for the type registered as canonical. It
+// returns an error if canonical is unknown, or old is already registered as
+// a different type. Aliasing a registered name to its own type does nothing.
+func (a *AliasRegistry) Alias(old, canonical string) error {
+	t, ok := a.TypeRegistry[canonical]
+	if !ok {
+		return ErrUnknownType{Name: canonical}
+	}
+	if other, ok := a.TypeRegistry[a.Resolve(old)]; ok && other != t {
+		return fmt.Errorf("typeregistry cannot alias %#v to %#v, it is already %s", old, canonical, other)
+	}
+	if _, ok := a.TypeRegistry[old]; ok {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.aliases[old] = canonical
+	return nil
+}
+
+// Aliases returns a copy of every alias, mapping the old name to the name it
+// resolves to.
+func (a *AliasRegistry) Aliases() map[string]string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	aliases := make(map[string]string, len(a.aliases))
+	for old, canonical := range a.aliases {
+		aliases[old] = canonical
+	}
+	return aliases
+}
+
+// Resolve returns the registered name that name is an alias of, or name if
+// it is not an alias.
+func (a *AliasRegistry) Resolve(name string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if canonical, ok := a.aliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// Add puts a new type in the registry.
+func (a *AliasRegistry) Add(o interface{}) string {
+	return a.TypeRegistry.Add(o)
+}
+
+// New instantiates a type by name or alias. If the name is unknown, it
+// panics.
+func (a *AliasRegistry) New(name string) interface{} {
+	return a.TypeRegistry.New(a.Resolve(name))
+}
+
+// Marshal encodes a type under its registered name.
+func (a *AliasRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return a.marshalWith(a, o)
+}
+
+func (a *AliasRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return a.TypeRegistry.marshalWith(outer, o)
+}
+
+func (a *AliasRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return a.TypeRegistry.appendWith(outer, dst, o)
+}
+
+// Unmarshal decodes a type by name or alias. If the name is unknown, it
+// panics.
+func (a *AliasRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return a.unmarshalWith(a, name, data, setup)
+}
+
+func (a *AliasRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return a.TypeRegistry.unmarshalWith(outer, a.Resolve(name), data, setup)
+}
+
+func (a *AliasRegistry) has(name string) bool {
+	return a.TypeRegistry.has(a.Resolve(name))
+}
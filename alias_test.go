@@ -0,0 +1,73 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAliasRegistry(t *testing.T) {
+	r := WithAliases(New())
+	name := r.Add(&jsonType{})
+	r.Add(&nameType{})
+
+	tests := []struct {
+		old, canonical string
+		err            string
+	}{
+		{old: "*oldpkg.jsonType", canonical: name},
+		{old: "*oldpkg.jsonType", canonical: name},
+		{old: "*typeregistry.nameType", canonical: name, err: `typeregistry cannot alias "*typeregistry.nameType" to "*typeregistry.jsonType", it is already *typeregistry.nameType`},
+		{old: "*oldpkg.other", canonical: "nope", err: `typeregistry does not know "nope"`},
+	}
+	for i, test := range tests {
+		err := r.Alias(test.old, test.canonical)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != test.err {
+			t.Errorf("%d Alias() got error %q, want %q", i, got, test.err)
+		}
+	}
+
+	got, err := r.Unmarshal("*oldpkg.jsonType", []byte(`{"ID":"x"}`), NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&jsonType{ID: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	if got, _, _ := r.Marshal(&jsonType{}); got != name {
+		t.Errorf("Marshal() name got %s, want %s", got, name)
+	}
+}
+
+func TestAliasRegistry_named(t *testing.T) {
+	r := WithAliases(New())
+	if err := r.TypeRegistry.AddNamed("event.v2", &jsonType{}); err != nil {
+		t.Fatalf("AddNamed() wants no error, got: %s", err)
+	}
+	if err := r.Alias("event.v1", "event.v2"); err != nil {
+		t.Fatalf("Alias() wants no error, got: %s", err)
+	}
+
+	name, data, err := r.Marshal(&jsonType{ID: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if name != "event.v2" {
+		t.Errorf("Marshal() name got %s, want event.v2", name)
+	}
+	if got, err := r.Unmarshal("event.v1", data, NoSetup); err != nil || !reflect.DeepEqual(got, &jsonType{ID: "x"}) {
+		t.Errorf("Unmarshal() of the alias got %#v, %v", got, err)
+	}
+	if got := r.TypeRegistry.Names(); !reflect.DeepEqual(got, []string{"event.v2"}) {
+		t.Errorf("Names() got %v, want only the canonical name", got)
+	}
+	if got, want := r.Aliases(), map[string]string{"event.v1": "event.v2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Aliases() got %v, want %v", got, want)
+	}
+	if got := r.New("event.v1"); !reflect.DeepEqual(got, &jsonType{}) {
+		t.Errorf("New() of the alias got %#v, want %#v", got, &jsonType{})
+	}
+}
@@ -0,0 +1,38 @@
+package typeregistry
+
+// AppendMarshaler is implemented by Marshaler types that can also encode
+// themselves onto the end of an existing buffer, to avoid allocating for each
+// value. The encoding must be the same as Marshal's.
+type AppendMarshaler interface {
+	MarshalAppend(dst []byte) ([]byte, error)
+}
+
+// MarshalAppend is Marshal, but appends the encoding to dst and returns the
+// extended buffer, so that a producer can reuse one buffer for many values.
+// Types implementing AppendMarshaler encode directly into dst when they are
+// marshaled with EncodingCustom, others are marshaled and copied.
+func (r TypeRegistry) MarshalAppend(dst []byte, o interface{}) (string, []byte, error) {
+	return r.appendWith(r, dst, o)
+}
+
+// MarshalAppend is TypeRegistry.MarshalAppend for any Registry. Wrappers that
+// do not change the data, such as a SafeRegistry, still let AppendMarshaler
+// types encode directly into dst.
+func MarshalAppend(r Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(r, r, dst, o)
+}
+
+func (r TypeRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	if m, ok := o.(AppendMarshaler); ok && marshalEncoding(o) == EncodingCustom {
+		name, err := r.marshalName(o)
+		if err != nil {
+			return name, dst, err
+		}
+		if err := normalize(o); err != nil {
+			return name, dst, err
+		}
+		data, err := m.MarshalAppend(dst)
+		return name, data, err
+	}
+	return appendMarshaled(r, outer, dst, o)
+}
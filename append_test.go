@@ -0,0 +1,107 @@
+package typeregistry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type appendType struct {
+	marshalType
+}
+
+func (a appendType) MarshalAppend(dst []byte) ([]byte, error) {
+	dst = append(dst, "bin:"...)
+	return append(dst, a.Name...), nil
+}
+
+func TestTypeRegistry_MarshalAppend(t *testing.T) {
+	tests := []struct {
+		o    interface{}
+		name string
+		want string
+		err  bool
+	}{
+		{o: appendType{marshalType{Name: "ok"}}, name: "typeregistry.appendType", want: "head:bin:ok"},
+		{o: marshalType{Name: "ok"}, name: "typeregistry.marshalType", want: "head:bin:ok"},
+		{o: nothingType{}, name: "typeregistry.nothingType", want: "head:"},
+		{o: marshalType{Fail: true}, name: "typeregistry.marshalType", err: true},
+	}
+	r := New()
+	for i, test := range tests {
+		buf := make([]byte, 0, 64)
+		buf = append(buf, "head:"...)
+		name, data, err := r.MarshalAppend(buf, test.o)
+		if name != test.name {
+			t.Errorf("%d MarshalAppend() name got %s, want %s", i, name, test.name)
+		}
+		if test.err {
+			if err == nil {
+				t.Errorf("%d MarshalAppend() wants error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d MarshalAppend() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%d MarshalAppend() got %q, want %q", i, data, test.want)
+		}
+		if &data[0] != &buf[0] {
+			t.Errorf("%d MarshalAppend() did not reuse the buffer", i)
+		}
+	}
+}
+
+func TestMarshalAppend_wrappers(t *testing.T) {
+	r := New()
+	r.Add(appendType{})
+	tests := []struct {
+		r    Registry
+		want string
+	}{
+		{r: Safe(r), want: "head:bin:ok"},
+		{r: WithAliases(r), want: "head:bin:ok"},
+		{r: MapNames(r, HashNames([]byte("key"))), want: "head:bin:ok"},
+		{r: NonNil(r), want: "head:bin:ok"},
+		{r: WithBlobStore(r, nil, 100), want: "head:\x00bin:ok"},
+	}
+	for i, test := range tests {
+		buf := make([]byte, 0, 64)
+		buf = append(buf, "head:"...)
+		_, data, err := MarshalAppend(test.r, buf, appendType{marshalType{Name: "ok"}})
+		if err != nil {
+			t.Errorf("%d MarshalAppend() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%d MarshalAppend() got %q, want %q", i, data, test.want)
+		}
+		if &data[0] != &buf[0] {
+			t.Errorf("%d MarshalAppend() did not reuse the buffer", i)
+		}
+	}
+}
+
+func TestAppendEnvelope(t *testing.T) {
+	r := New()
+	r.Add(appendType{})
+	r.Add(&jsonType{})
+	for i, o := range []interface{}{appendType{marshalType{Name: strings.Repeat("x", 200)}}, &jsonType{ID: "x"}} {
+		want, err := r.EncodeEnvelope(o)
+		if err != nil {
+			t.Fatalf("%d EncodeEnvelope() wants no error, got: %s", i, err)
+		}
+		buf := make([]byte, 0, 512)
+		buf = append(buf, "head:"...)
+		got, err := r.AppendEnvelope(buf, o)
+		if err != nil {
+			t.Fatalf("%d AppendEnvelope() wants no error, got: %s", i, err)
+		}
+		if !bytes.Equal(got, append([]byte("head:"), want...)) {
+			t.Errorf("%d AppendEnvelope() got %q, want head: and %q", i, got, want)
+		}
+		if &got[0] != &buf[0] {
+			t.Errorf("%d AppendEnvelope() did not reuse the buffer", i)
+		}
+	}
+}
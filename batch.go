@@ -0,0 +1,140 @@
+package typeregistry
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// errTruncated is returned when framed data ends before it should.
+var errTruncated = errors.New("typeregistry data is truncated")
+
+// MarshalBatch encodes many objects, which may be of different types, into a
+// single container. The container holds the number of objects followed by the
+// registered name and marshaled bytes of each, in order, so the same items
+// always produce the same bytes. If any items fail to marshal, it returns a
+// BatchError holding each failure.
+func (r TypeRegistry) MarshalBatch(items []interface{}) ([]byte, error) {
+	return MarshalBatch(r, items)
+}
+
+// AppendBatch is MarshalBatch, but appends the container to dst and returns
+// the extended buffer. Items are marshaled with MarshalAppend into a scratch
+// buffer that is reused for the whole batch.
+func (r TypeRegistry) AppendBatch(dst []byte, items []interface{}) ([]byte, error) {
+	return AppendBatch(r, dst, items)
+}
+
+// MarshalBatch is TypeRegistry.MarshalBatch for any Registry, so that a
+// wrapper such as an EncodedRegistry marshals each item.
+func MarshalBatch(r Registry, items []interface{}) ([]byte, error) {
+	return AppendBatch(r, nil, items)
+}
+
+// AppendBatch is TypeRegistry.AppendBatch for any Registry.
+func AppendBatch(r Registry, dst []byte, items []interface{}) ([]byte, error) {
+	buf := appendUvarint(dst, uint64(len(items)))
+	var (
+		scratch []byte
+		errs    BatchError
+	)
+	for i, o := range items {
+		name, data, err := MarshalAppend(r, scratch[:0], o)
+		if err != nil {
+			errs = append(errs, &ItemError{Index: i, Name: name, Err: err})
+			continue
+		}
+		buf = appendFrame(buf, name, data)
+		scratch = data
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return buf, nil
+}
+
+// UnmarshalBatch decodes a container created by MarshalBatch. The setup
+// function is called for each object, as in Unmarshal. If any items fail to
+// unmarshal, the rest are still decoded, and it returns every item along with
+// a BatchError holding each failure. An item whose name is unknown fails with
+// ErrUnknownType.
+func (r TypeRegistry) UnmarshalBatch(data []byte, setup SetupFunc) ([]interface{}, error) {
+	return UnmarshalBatch(r, data, setup)
+}
+
+// UnmarshalBatch is TypeRegistry.UnmarshalBatch for any Registry.
+func UnmarshalBatch(r Registry, data []byte, setup SetupFunc) ([]interface{}, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errTruncated
+	}
+	data = data[n:]
+	if count > uint64(len(data)) {
+		return nil, errTruncated
+	}
+	var (
+		items = make([]interface{}, 0, count)
+		errs  BatchError
+	)
+	for i := uint64(0); i < count; i++ {
+		name, payload, rest, err := readFrame(data)
+		if err != nil {
+			return items, err
+		}
+		data = rest
+		o, err := unmarshalE(r, name, payload, setup)
+		if err != nil {
+			errs = append(errs, &ItemError{Index: int(i), Name: name, Err: err})
+		}
+		items = append(items, o)
+	}
+	if errs != nil {
+		return items, errs
+	}
+	return items, nil
+}
+
+// appendFrame appends a name and its data to buf, each prefixed by its length.
+func appendFrame(buf []byte, name string, data []byte) []byte {
+	buf = appendUvarint(buf, uint64(len(name)))
+	buf = append(buf, name...)
+	buf = appendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// readFrame reads a frame written by appendFrame and returns the data that
+// follows it.
+func readFrame(buf []byte) (name string, data []byte, rest []byte, err error) {
+	b, buf, err := readBytes(buf)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	data, rest, err = readBytes(buf)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return string(b), data, rest, nil
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+// uvarintLen returns the number of bytes that appendUvarint uses for x.
+func uvarintLen(x uint64) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
+	}
+	return n
+}
+
+func readBytes(buf []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(buf)
+	if n <= 0 || size > uint64(len(buf)-n) {
+		return nil, nil, errTruncated
+	}
+	end := n + int(size)
+	return buf[n:end:end], buf[end:], nil
+}
@@ -0,0 +1,63 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_MarshalBatch(t *testing.T) {
+	r := New()
+	r.Add(nothingType{})
+	r.Add(marshalType{})
+	r.Add(&nameType{})
+
+	items := []interface{}{
+		nothingType{},
+		marshalType{Name: "ok"},
+		&nameType{Name: "lost"},
+	}
+	data, err := r.MarshalBatch(items)
+	if err != nil {
+		t.Fatalf("MarshalBatch() wants no error, got: %s", err)
+	}
+	got, err := r.UnmarshalBatch(data, func(i interface{}) {
+		if x, ok := i.(*nameType); ok {
+			x.Name = "setup"
+		}
+	})
+	if err != nil {
+		t.Fatalf("UnmarshalBatch() wants no error, got: %s", err)
+	}
+	want := []interface{}{
+		nothingType{},
+		marshalType{},
+		&nameType{Name: "setup"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalBatch() got %#v, want %#v", got, want)
+	}
+
+	golden := "\x03\x18typeregistry.nothingType\x00" +
+		"\x18typeregistry.marshalType\x06bin:ok" +
+		"\x16*typeregistry.nameType\x00"
+	if string(data) != golden {
+		t.Errorf("MarshalBatch() got %q, want %q", data, golden)
+	}
+
+	appended, err := r.AppendBatch([]byte("head"), items)
+	if err != nil {
+		t.Errorf("AppendBatch() wants no error, got: %s", err)
+	}
+	if string(appended) != "head"+golden {
+		t.Errorf("AppendBatch() got %q, want %q", appended, "head"+golden)
+	}
+
+	if _, err := r.MarshalBatch([]interface{}{marshalType{Fail: true}}); err == nil {
+		t.Errorf("MarshalBatch() of a failing item wants error, got none")
+	}
+	for i := 0; i < len(data); i++ {
+		if _, err := r.UnmarshalBatch(data[:i], NoSetup); err == nil {
+			t.Errorf("UnmarshalBatch() of %d truncated bytes wants error, got none", i)
+		}
+	}
+}
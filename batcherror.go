@@ -0,0 +1,35 @@
+package typeregistry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemError is the failure of one item in a batch.
+type ItemError struct {
+	// Index is the position of the item in the batch.
+	Index int
+	// Name is the registered name of the item's type.
+	Name string
+	// Err is the error marshaling or unmarshaling the item.
+	Err error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("typeregistry batch item %d (%s): %s", e.Index, e.Name, e.Err)
+}
+
+// Unwrap returns the item's error.
+func (e *ItemError) Unwrap() error { return e.Err }
+
+// BatchError holds the failure of every item that failed in a batch
+// operation, in order, so that tools can report on each one.
+type BatchError []*ItemError
+
+func (e BatchError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,62 @@
+package typeregistry
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBatchError(t *testing.T) {
+	r := New()
+	r.Add(marshalType{})
+	r.Add(&unmarshalType{})
+	r.Add(&unmarshalFailType{})
+
+	_, err := r.MarshalBatch([]interface{}{
+		marshalType{Fail: true},
+		marshalType{Name: "ok"},
+		marshalType{Fail: true},
+	})
+	var errs BatchError
+	if !errors.As(err, &errs) {
+		t.Fatalf("MarshalBatch() got %v, want a BatchError", err)
+	}
+	if len(errs) != 2 || errs[0].Index != 0 || errs[1].Index != 2 || errs[1].Name != "typeregistry.marshalType" {
+		t.Errorf("MarshalBatch() got errors %v, want items 0 and 2", errs)
+	}
+
+	data, err := r.MarshalBatch([]interface{}{&unmarshalFailType{}, &unmarshalType{}})
+	if err != nil {
+		t.Fatalf("MarshalBatch() wants no error, got: %s", err)
+	}
+	items, err := r.UnmarshalBatch(data, NoSetup)
+	if !errors.As(err, &errs) {
+		t.Fatalf("UnmarshalBatch() got %v, want a BatchError", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("UnmarshalBatch() got %d items, want 2", len(items))
+	}
+	if len(errs) != 1 || errs[0].Index != 0 || errs[0].Name != "*typeregistry.unmarshalFailType" || errors.Unwrap(errs[0]) == nil {
+		t.Errorf("UnmarshalBatch() got errors %v, want item 0", errs)
+	}
+	if want := "typeregistry batch item 0 (*typeregistry.unmarshalFailType): " + errs[0].Err.Error(); err.Error() != want {
+		t.Errorf("Error() got %q, want %q", err.Error(), want)
+	}
+
+	data = appendFrame(appendUvarint(nil, 2), "nope", nil)
+	data = appendFrame(data, "*typeregistry.unmarshalType", []byte("a"))
+	items, err = r.UnmarshalBatch(data, NoSetup)
+	if !errors.As(err, &errs) {
+		t.Fatalf("UnmarshalBatch() of an unknown name got %v, want a BatchError", err)
+	}
+	if len(items) != 2 || !reflect.DeepEqual(items[1], &unmarshalType{Name: "bin:a"}) {
+		t.Errorf("UnmarshalBatch() of an unknown name got items %#v", items)
+	}
+	if len(errs) != 1 || errs[0].Index != 0 || errs[0].Err != (ErrUnknownType{Name: "nope"}) {
+		t.Errorf("UnmarshalBatch() of an unknown name got errors %v, want ErrUnknownType for item 0", errs)
+	}
+	_, err = UnmarshalBatch(WithEncodings(r), data, NoSetup)
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Err != (ErrUnknownType{Name: "nope"}) {
+		t.Errorf("UnmarshalBatch() of a wrapper got %v, want ErrUnknownType for item 0", err)
+	}
+}
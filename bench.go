@@ -0,0 +1,68 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// benchmarkTime is roughly how long BenchmarkCodecs measures each operation.
+const benchmarkTime = 10 * time.Millisecond
+
+// CodecResult is the measurement of one codec on one sample.
+type CodecResult struct {
+	// Name is the registered name of the sample's type.
+	Name string
+	// Codec is the name of the codec.
+	Codec string
+	// Size is the number of bytes the sample encodes to.
+	Size int
+	// Marshal and Unmarshal are the average time each operation takes.
+	Marshal   time.Duration
+	Unmarshal time.Duration
+}
+
+// BenchmarkCodecs measures the encoded size and speed of each codec on each
+// sample, so that a wire format can be chosen using real data. Results are in
+// the order of samples, then codecs. It returns an error if a codec fails.
+func (r TypeRegistry) BenchmarkCodecs(samples []interface{}, codecs ...Codec) ([]CodecResult, error) {
+	var results []CodecResult
+	for _, o := range samples {
+		name := r.name(o)
+		for _, c := range codecs {
+			data, err := c.Marshal(o)
+			if err != nil {
+				return nil, fmt.Errorf("typeregistry %s cannot marshal %s: %s", c.Name(), name, err)
+			}
+			target := reflect.TypeOf(o)
+			if err := c.Unmarshal(data, reflect.New(target).Interface()); err != nil {
+				return nil, fmt.Errorf("typeregistry %s cannot unmarshal %s: %s", c.Name(), name, err)
+			}
+			results = append(results, CodecResult{
+				Name:  name,
+				Codec: c.Name(),
+				Size:  len(data),
+				Marshal: measure(func() {
+					c.Marshal(o)
+				}),
+				Unmarshal: measure(func() {
+					c.Unmarshal(data, reflect.New(target).Interface())
+				}),
+			})
+		}
+	}
+	return results, nil
+}
+
+// measure returns the average time f takes over about benchmarkTime.
+func measure(f func()) time.Duration {
+	var (
+		n     int
+		start = time.Now()
+	)
+	for n == 0 || time.Since(start) < benchmarkTime {
+		f()
+		n++
+	}
+	return time.Since(start) / time.Duration(n)
+}
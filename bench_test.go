@@ -0,0 +1,54 @@
+package typeregistry
+
+import (
+	"fmt"
+	"testing"
+)
+
+// failCodec is a Codec that cannot encode anything.
+type failCodec struct{}
+
+func (failCodec) Name() string                               { return "fail" }
+func (failCodec) Marshal(o interface{}) ([]byte, error)      { return nil, fmt.Errorf("Failed") }
+func (failCodec) Unmarshal(data []byte, o interface{}) error { return fmt.Errorf("Failed") }
+
+func TestTypeRegistry_BenchmarkCodecs(t *testing.T) {
+	r := New()
+	samples := []interface{}{
+		&nameType{Name: "ok"},
+		marshalType{Name: "ok"},
+	}
+	got, err := r.BenchmarkCodecs(samples, JSONCodec, GobCodec)
+	if err != nil {
+		t.Fatalf("BenchmarkCodecs() wants no error, got: %s", err)
+	}
+	want := []struct {
+		name  string
+		codec string
+		size  int
+	}{
+		{name: "*typeregistry.nameType", codec: "json", size: len(`{"Name":"ok"}`)},
+		{name: "*typeregistry.nameType", codec: "gob"},
+		{name: "typeregistry.marshalType", codec: "json", size: len(`{"Name":"ok","Fail":false}`)},
+		{name: "typeregistry.marshalType", codec: "gob"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("BenchmarkCodecs() got %d results, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Name != w.name || g.Codec != w.codec {
+			t.Errorf("%d BenchmarkCodecs() got %s %s, want %s %s", i, g.Name, g.Codec, w.name, w.codec)
+		}
+		if (w.size != 0 && g.Size != w.size) || g.Size == 0 {
+			t.Errorf("%d BenchmarkCodecs() size got %d, want %d", i, g.Size, w.size)
+		}
+		if g.Marshal <= 0 || g.Unmarshal <= 0 {
+			t.Errorf("%d BenchmarkCodecs() got times %s %s, want them measured", i, g.Marshal, g.Unmarshal)
+		}
+	}
+
+	if _, err := r.BenchmarkCodecs(samples, failCodec{}); err == nil {
+		t.Errorf("BenchmarkCodecs() with a failing codec wants error, got none")
+	}
+}
@@ -0,0 +1,131 @@
+package typeregistry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// BlobStore holds payloads that are too large to send inline, as in the
+// claim-check pattern.
+type BlobStore interface {
+	// Put stores data and returns a reference to it.
+	Put(data []byte) (ref string, err error)
+	// Get returns the data stored under ref.
+	Get(ref string) ([]byte, error)
+}
+
+const (
+	blobInline byte = iota
+	blobRef
+)
+
+// BlobRegistry is a Registry that moves marshaled data larger than Threshold
+// bytes into a BlobStore, so that only a reference to it is sent. Both sides
+// must use a BlobRegistry, because the data it marshals is prefixed with a
+// byte that marks it as inline or a reference.
+type BlobRegistry struct {
+	Registry  Registry
+	Store     BlobStore
+	Threshold int
+}
+
+// WithBlobStore wraps a registry so that data larger than threshold bytes is
+// put in store by Marshal and fetched from it by Unmarshal.
+func WithBlobStore(r Registry, store BlobStore, threshold int) *BlobRegistry {
+	return &BlobRegistry{Registry: r, Store: store, Threshold: threshold}
+}
+
+// Add puts a new type in the registry.
+func (b *BlobRegistry) Add(o interface{}) string {
+	return b.Registry.Add(o)
+}
+
+// New instantiates a type by name.
+func (b *BlobRegistry) New(name string) interface{} {
+	return b.Registry.New(name)
+}
+
+// Marshal encodes a type, replacing its data with a reference if it is
+// larger than the threshold.
+func (b *BlobRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return b.marshalWith(b, o)
+}
+
+func (b *BlobRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	name, data, err := marshalVia(b.Registry, outer, o)
+	if err != nil {
+		return name, nil, err
+	}
+	if len(data) <= b.Threshold {
+		return name, append([]byte{blobInline}, data...), nil
+	}
+	ref, err := b.Store.Put(data)
+	if err != nil {
+		return name, nil, err
+	}
+	return name, append([]byte{blobRef}, ref...), nil
+}
+
+func (b *BlobRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendMarshaled(b, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name, first fetching its data from the store
+// if it was replaced by a reference.
+func (b *BlobRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return b.unmarshalWith(b, name, data, setup)
+}
+
+func (b *BlobRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, errTruncated
+	}
+	switch data[0] {
+	case blobInline:
+		return unmarshalVia(b.Registry, outer, name, data[1:], setup)
+	case blobRef:
+		blob, err := b.Store.Get(string(data[1:]))
+		if err != nil {
+			return nil, err
+		}
+		return unmarshalVia(b.Registry, outer, name, blob, setup)
+	}
+	return nil, errors.New("typeregistry data is not from a BlobRegistry")
+}
+
+func (b *BlobRegistry) has(name string) bool {
+	return knows(b.Registry, name)
+}
+
+// MemoryBlobStore is a BlobStore that keeps payloads in memory, for tests.
+type MemoryBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+// NewMemoryBlobStore initializes an empty MemoryBlobStore.
+func NewMemoryBlobStore() *MemoryBlobStore {
+	return &MemoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+// Put stores a copy of data under a sequential reference.
+func (m *MemoryBlobStore) Put(data []byte) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ref := "blob-" + strconv.Itoa(len(m.blobs)+1)
+	m.blobs[ref] = append([]byte{}, data...)
+	return ref, nil
+}
+
+// Get returns the data stored under ref.
+func (m *MemoryBlobStore) Get(ref string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.blobs[ref]
+	if !ok {
+		return nil, fmt.Errorf("typeregistry blob %#v is not in the store", ref)
+	}
+	return data, nil
+}
@@ -0,0 +1,52 @@
+package typeregistry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithBlobStore(t *testing.T) {
+	store := NewMemoryBlobStore()
+	r := WithBlobStore(New(), store, 30)
+	name := r.Add(&jsonType{})
+
+	tests := []struct {
+		o    *jsonType
+		data string
+	}{
+		{
+			o:    &jsonType{},
+			data: "\x00{\"ID\":\"\",\"Tags\":null}",
+		},
+		{
+			o:    &jsonType{ID: strings.Repeat("x", 20)},
+			data: "\x01blob-1",
+		},
+	}
+	for i, test := range tests {
+		mname, data, err := r.Marshal(test.o)
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if mname != name {
+			t.Errorf("%d Marshal() name got %s, want %s", i, mname, name)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d Marshal() got %q, want %q", i, data, test.data)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.o) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.o)
+		}
+	}
+
+	for i, data := range []string{"", "\x02", "\x01blob-9"} {
+		if _, err := r.Unmarshal(name, []byte(data), NoSetup); err == nil {
+			t.Errorf("%d Unmarshal(%q) wants error, got none", i, data)
+		}
+	}
+}
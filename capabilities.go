@@ -0,0 +1,77 @@
+package typeregistry
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Capabilities are flags describing what the registry can do with a type
+// beyond instantiating it.
+type Capabilities uint8
+
+const (
+	// Marshalable types have an encoding of their own: they implement
+	// RegistryMarshaler, Marshaler, or a standard library marshaler interface,
+	// are an Enum, embed JSON, or are a standard library type added by
+	// AddStdlib.
+	Marshalable Capabilities = 1 << iota
+	// Unmarshalable types can decode their encoding: they implement
+	// RegistryUnmarshaler, Unmarshaler, or a standard library unmarshaler
+	// interface, are an Enum, embed JSON, or are a standard library type added
+	// by AddStdlib.
+	Unmarshalable
+	// Viewable types implement Viewer.
+	Viewable
+)
+
+// Has reports whether c includes all of the flags in f.
+func (c Capabilities) Has(f Capabilities) bool {
+	return c&f == f
+}
+
+var (
+	registryMarshalerType   = reflect.TypeOf((*RegistryMarshaler)(nil)).Elem()
+	registryUnmarshalerType = reflect.TypeOf((*RegistryUnmarshaler)(nil)).Elem()
+	marshalerType           = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType         = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	enumType                = reflect.TypeOf((*Enum)(nil)).Elem()
+	jsonSelfType            = reflect.TypeOf((*jsonSelf)(nil)).Elem()
+)
+
+// capabilityCache holds the Capabilities of each type, computed when it is
+// added.
+var capabilityCache sync.Map
+
+// Capabilities returns what the registry can do with the type registered as
+// name, so that code building on the registry can decide how to handle a type
+// without asserting each interface itself. If the name is unknown, it panics.
+func (r TypeRegistry) Capabilities(name string) Capabilities {
+	t, ok := r[name]
+	if !ok {
+		r.New(name)
+	}
+	return capabilitiesOf(t)
+}
+
+// capabilitiesOf returns the Capabilities of t, computing them the first time.
+func capabilitiesOf(t reflect.Type) Capabilities {
+	if c, ok := capabilityCache.Load(t); ok {
+		return c.(Capabilities)
+	}
+	var c Capabilities
+	if t.Implements(registryMarshalerType) || t.Implements(marshalerType) ||
+		t.Implements(enumType) || t.Implements(jsonSelfType) || hasStd(t) ||
+		t.Implements(binaryMarshalerType) || t.Implements(textMarshalerType) || t.Implements(jsonMarshalerType) {
+		c |= Marshalable
+	}
+	if t.Implements(registryUnmarshalerType) || t.Implements(unmarshalerType) ||
+		t.Implements(enumType) || t.Implements(jsonSelfType) || hasStd(t) ||
+		canUnmarshalStd(t, EncodingBinary) || canUnmarshalStd(t, EncodingText) || canUnmarshalStd(t, EncodingJSONMarshaler) {
+		c |= Unmarshalable
+	}
+	if t.Implements(viewerType) {
+		c |= Viewable
+	}
+	capabilityCache.Store(t, c)
+	return c
+}
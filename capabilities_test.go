@@ -0,0 +1,44 @@
+package typeregistry
+
+import (
+	"testing"
+)
+
+func TestTypeRegistry_Capabilities(t *testing.T) {
+	tests := []struct {
+		t    interface{}
+		want Capabilities
+	}{
+		{t: nothingType{}, want: 0},
+		{t: marshalType{}, want: Marshalable},
+		{t: &unmarshalType{}, want: Unmarshalable},
+		{t: colorType(""), want: Marshalable | Unmarshalable},
+		{t: jsonType{}, want: Marshalable | Unmarshalable},
+		{t: viewType{}, want: Viewable},
+		{t: ifaceType{}, want: Marshalable | Unmarshalable},
+	}
+	for i, test := range tests {
+		r := New()
+		name := r.Add(test.t)
+		if got := r.Capabilities(name); got != test.want {
+			t.Errorf("%d Capabilities() got %b, want %b", i, got, test.want)
+		}
+	}
+
+	if c := Marshalable | Viewable; !c.Has(Viewable) || c.Has(Marshalable|Unmarshalable) {
+		t.Errorf("Has() got wrong result for %b", c)
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		New().Capabilities("nope")
+	}()
+	if paniced != "typeregistry does not know \"nope\"" {
+		t.Errorf("Expected Capabilities() of an unknown name to panic, got %s", paniced)
+	}
+}
@@ -0,0 +1,127 @@
+package typeregistry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// chunkHeaderSize is the most bytes the index, count and value ID of a chunk
+// can use.
+const chunkHeaderSize = 2*binary.MaxVarintLen64 + chunkIDSize
+
+// chunkIDSize is the size of the value ID, a hash of the name and data, that
+// each chunk carries.
+const chunkIDSize = 8
+
+// MarshalChunks encodes a type like Marshal, then splits the registered name
+// and data into chunks of at most size bytes, for transports that limit the
+// size of a message. Each chunk records its index, the number of chunks, and
+// an ID of the value, so UnmarshalChunks can reassemble them in any order and
+// tell them apart from the chunks of other values. It returns an error if
+// size is too small to hold any data.
+func (r TypeRegistry) MarshalChunks(o interface{}, size int) ([][]byte, error) {
+	return MarshalChunks(r, o, size)
+}
+
+// MarshalChunks is TypeRegistry.MarshalChunks for any Registry.
+func MarshalChunks(r Registry, o interface{}, size int) ([][]byte, error) {
+	if size <= chunkHeaderSize {
+		return nil, fmt.Errorf("typeregistry chunk size must be greater than %d, got %d", chunkHeaderSize, size)
+	}
+	name, data, err := r.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	payload := appendFrame(nil, name, data)
+	id := chunkID(payload)
+	max := size - chunkHeaderSize
+	count := (len(payload) + max - 1) / max
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * max
+		if end > len(payload) {
+			end = len(payload)
+		}
+		chunk := appendUvarint(nil, uint64(i))
+		chunk = appendUvarint(chunk, uint64(count))
+		chunk = append(chunk, id...)
+		chunks = append(chunks, append(chunk, payload[i*max:end]...))
+	}
+	return chunks, nil
+}
+
+// UnmarshalChunks reassembles chunks created by MarshalChunks, in any order,
+// and decodes the type like Unmarshal. It returns an error if a chunk is
+// missing, repeated, or from a different value, and ErrUnknownType if the
+// name is unknown.
+func (r TypeRegistry) UnmarshalChunks(chunks [][]byte, setup SetupFunc) (interface{}, error) {
+	return UnmarshalChunks(r, chunks, setup)
+}
+
+// UnmarshalChunks is TypeRegistry.UnmarshalChunks for any Registry.
+func UnmarshalChunks(r Registry, chunks [][]byte, setup SetupFunc) (interface{}, error) {
+	type piece struct {
+		index uint64
+		data  []byte
+	}
+	if len(chunks) == 0 {
+		return nil, errors.New("typeregistry has no chunks to unmarshal")
+	}
+	var id []byte
+	pieces := make([]piece, 0, len(chunks))
+	for _, chunk := range chunks {
+		index, n := binary.Uvarint(chunk)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		count, m := binary.Uvarint(chunk[n:])
+		if m <= 0 {
+			return nil, errTruncated
+		}
+		if count != uint64(len(chunks)) {
+			return nil, fmt.Errorf("typeregistry chunk %d is one of %d, got %d chunks", index, count, len(chunks))
+		}
+		chunk = chunk[n+m:]
+		if len(chunk) < chunkIDSize {
+			return nil, errTruncated
+		}
+		if id == nil {
+			id = chunk[:chunkIDSize]
+		} else if !bytes.Equal(chunk[:chunkIDSize], id) {
+			return nil, fmt.Errorf("typeregistry chunk %d is from a different value", index)
+		}
+		pieces = append(pieces, piece{index: index, data: chunk[chunkIDSize:]})
+	}
+	sort.Slice(pieces, func(i, j int) bool {
+		return pieces[i].index < pieces[j].index
+	})
+	var payload []byte
+	for i, p := range pieces {
+		if p.index != uint64(i) {
+			return nil, fmt.Errorf("typeregistry chunk %d is missing", i)
+		}
+		payload = append(payload, p.data...)
+	}
+	if !bytes.Equal(chunkID(payload), id) {
+		return nil, errors.New("typeregistry chunks do not match their value ID")
+	}
+	name, data, rest, err := readFrame(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("typeregistry chunks have %d bytes of trailing data", len(rest))
+	}
+	return unmarshalE(r, name, data, setup)
+}
+
+// chunkID returns the value ID of a chunked payload.
+func chunkID(payload []byte) []byte {
+	h := fnv.New64a()
+	h.Write(payload)
+	return h.Sum(nil)
+}
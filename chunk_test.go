@@ -0,0 +1,77 @@
+package typeregistry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypeRegistry_MarshalChunks(t *testing.T) {
+	r := New()
+	r.Add(&unmarshalType{})
+	r.Add(marshalType{})
+
+	tests := []struct {
+		size  int
+		count int
+	}{
+		{size: 1000, count: 1},
+		{size: 48, count: 7},
+		{size: 29, count: 130},
+	}
+	o := marshalType{Name: strings.Repeat("x", 100)}
+	for i, test := range tests {
+		chunks, err := r.MarshalChunks(o, test.size)
+		if err != nil {
+			t.Fatalf("%d MarshalChunks() wants no error, got: %s", i, err)
+		}
+		if len(chunks) != test.count {
+			t.Errorf("%d MarshalChunks() got %d chunks, want %d", i, len(chunks), test.count)
+		}
+		for j, c := range chunks {
+			if len(c) > test.size {
+				t.Errorf("%d MarshalChunks() chunk %d has %d bytes, want at most %d", i, j, len(c), test.size)
+			}
+		}
+		for j := 0; j < len(chunks)/2; j++ {
+			chunks[j], chunks[len(chunks)-1-j] = chunks[len(chunks)-1-j], chunks[j]
+		}
+		got, err := r.UnmarshalChunks(chunks, NoSetup)
+		if err != nil {
+			t.Errorf("%d UnmarshalChunks() wants no error, got: %s", i, err)
+		}
+		if want := (marshalType{}); !reflect.DeepEqual(got, want) {
+			t.Errorf("%d UnmarshalChunks() got %#v, want %#v", i, got, want)
+		}
+	}
+
+	if _, err := r.MarshalChunks(o, 28); err == nil {
+		t.Errorf("MarshalChunks() with a tiny size wants error, got none")
+	}
+	if _, err := r.MarshalChunks(marshalType{Fail: true}, 100); err == nil {
+		t.Errorf("MarshalChunks() of a failing value wants error, got none")
+	}
+
+	chunks, _ := r.MarshalChunks(marshalType{Name: "a"}, 32)
+	other, _ := r.MarshalChunks(marshalType{Name: "b"}, 32)
+	corrupt := append([]byte(nil), chunks[0]...)
+	corrupt[len(corrupt)-1]++
+	bad := [][][]byte{
+		nil,
+		chunks[1:],
+		append([][]byte{chunks[1]}, chunks[1:]...),
+		{{}},
+		append([][]byte{other[0]}, chunks[1:]...),
+		append([][]byte{corrupt}, chunks[1:]...),
+	}
+	for i, b := range bad {
+		if _, err := r.UnmarshalChunks(b, NoSetup); err == nil {
+			t.Errorf("%d UnmarshalChunks() of bad chunks wants error, got none", i)
+		}
+	}
+
+	_, err := New().UnmarshalChunks(chunks, NoSetup)
+	if want := (ErrUnknownType{Name: "typeregistry.marshalType"}); err != want {
+		t.Errorf("UnmarshalChunks() of an unknown name got %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,43 @@
+package typeregistry
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec encodes and decodes values in one wire format.
+type Codec interface {
+	// Name identifies the format, such as "json".
+	Name() string
+	// Marshal encodes o.
+	Marshal(o interface{}) ([]byte, error)
+	// Unmarshal decodes data into o, which must be a pointer.
+	Unmarshal(data []byte, o interface{}) error
+}
+
+// JSONCodec is a Codec using encoding/json.
+var JSONCodec Codec = jsonCodec{}
+
+// GobCodec is a Codec using encoding/gob.
+var GobCodec Codec = gobCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(o interface{}) ([]byte, error)      { return json.Marshal(o) }
+func (jsonCodec) Unmarshal(data []byte, o interface{}) error { return json.Unmarshal(data, o) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(o interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(o)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, o interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(o)
+}
@@ -0,0 +1,26 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodecs(t *testing.T) {
+	for _, c := range []Codec{JSONCodec, GobCodec} {
+		in := &nameType{Name: "ok"}
+		data, err := c.Marshal(in)
+		if err != nil {
+			t.Errorf("%s Marshal() wants no error, got: %s", c.Name(), err)
+		}
+		out := &nameType{}
+		if err := c.Unmarshal(data, out); err != nil {
+			t.Errorf("%s Unmarshal() wants no error, got: %s", c.Name(), err)
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Errorf("%s Unmarshal() got %#v, want %#v", c.Name(), out, in)
+		}
+		if err := c.Unmarshal([]byte("bad"), out); err == nil {
+			t.Errorf("%s Unmarshal() of bad data wants error, got none", c.Name())
+		}
+	}
+}
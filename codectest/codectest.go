@@ -0,0 +1,135 @@
+// Package codectest checks that a typeregistry.Codec behaves as the registry
+// expects, so that codecs written outside this package can prove they are
+// compatible.
+package codectest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+// Inner is a nested struct in Sample.
+type Inner struct {
+	Label string
+	Count int
+}
+
+// Sample is the value that Run encodes, covering the kinds of fields
+// registered types commonly have. A codec must be able to encode it.
+type Sample struct {
+	String  string
+	Int     int64
+	Uint    uint32
+	Float   float64
+	Bool    bool
+	Bytes   []byte
+	Strings []string
+	Map     map[string]int
+	Inner   Inner
+	Ptr     *Inner
+}
+
+// Run checks codec c with round trips, zero values, nil fields, a large
+// payload, and bad input, each as a subtest.
+func Run(t *testing.T, c typeregistry.Codec) {
+	t.Run("Name", func(t *testing.T) {
+		if c.Name() == "" {
+			t.Errorf("Name() wants a name, got none")
+		}
+	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		in := &Sample{
+			String:  "héllo, \"world\"\n",
+			Int:     -1 << 40,
+			Uint:    1<<32 - 1,
+			Float:   3.25,
+			Bool:    true,
+			Bytes:   []byte{0, 1, 255},
+			Strings: []string{"a", "", "c"},
+			Map:     map[string]int{"a": 1, "b": -2},
+			Inner:   Inner{Label: "inner", Count: 3},
+			Ptr:     &Inner{Label: "ptr", Count: 4},
+		}
+		if out := roundTrip(t, c, in); out != nil && !reflect.DeepEqual(in, out) {
+			t.Errorf("round trip got %#v, want %#v", out, in)
+		}
+	})
+	t.Run("Zero", func(t *testing.T) {
+		out := roundTrip(t, c, &Sample{})
+		if out == nil {
+			return
+		}
+		normalizeEmpty(out)
+		if !reflect.DeepEqual(out, &Sample{}) {
+			t.Errorf("round trip of the zero value got %#v", out)
+		}
+	})
+	t.Run("Nil", func(t *testing.T) {
+		out := roundTrip(t, c, &Sample{String: "x"})
+		if out == nil {
+			return
+		}
+		if out.Ptr != nil {
+			t.Errorf("round trip of a nil pointer got %#v, want nil", out.Ptr)
+		}
+		if len(out.Bytes) != 0 || len(out.Strings) != 0 || len(out.Map) != 0 {
+			t.Errorf("round trip of nil slices and maps got %#v", out)
+		}
+	})
+	t.Run("Large", func(t *testing.T) {
+		in := &Sample{String: strings.Repeat("x", 1<<20), Bytes: make([]byte, 1<<20)}
+		for i := range in.Bytes {
+			in.Bytes[i] = byte(i)
+		}
+		if out := roundTrip(t, c, in); out != nil && !reflect.DeepEqual(in, out) {
+			t.Errorf("round trip of a large payload differs")
+		}
+	})
+	t.Run("BadData", func(t *testing.T) {
+		if err := c.Unmarshal([]byte("\xff\x00not valid"), &Sample{}); err == nil {
+			t.Errorf("Unmarshal() of bad data wants error, got none")
+		}
+	})
+	t.Run("NotPointer", func(t *testing.T) {
+		data, err := c.Marshal(&Sample{String: "x"})
+		if err != nil {
+			t.Fatalf("Marshal() wants no error, got: %s", err)
+		}
+		if err := c.Unmarshal(data, Sample{}); err == nil {
+			t.Errorf("Unmarshal() into a non-pointer wants error, got none")
+		}
+	})
+}
+
+// roundTrip marshals and unmarshals in, returning nil if either fails.
+func roundTrip(t *testing.T, c typeregistry.Codec, in *Sample) *Sample {
+	t.Helper()
+	data, err := c.Marshal(in)
+	if err != nil {
+		t.Errorf("Marshal() wants no error, got: %s", err)
+		return nil
+	}
+	out := &Sample{}
+	if err := c.Unmarshal(data, out); err != nil {
+		t.Errorf("Unmarshal() wants no error, got: %s", err)
+		return nil
+	}
+	return out
+}
+
+// normalizeEmpty sets empty slices and maps in s to nil, since codecs may
+// decode either.
+func normalizeEmpty(s *Sample) {
+	if len(s.Bytes) == 0 {
+		s.Bytes = nil
+	}
+	if len(s.Strings) == 0 {
+		s.Strings = nil
+	}
+	if len(s.Map) == 0 {
+		s.Map = nil
+	}
+}
@@ -0,0 +1,15 @@
+package codectest
+
+import (
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+func TestRun(t *testing.T) {
+	for _, c := range []typeregistry.Codec{typeregistry.JSONCodec, typeregistry.GobCodec} {
+		t.Run(c.Name(), func(t *testing.T) {
+			Run(t, c)
+		})
+	}
+}
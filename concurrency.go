@@ -0,0 +1,99 @@
+package typeregistry
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ConcurrentRegistry is a Registry that limits how many Unmarshals of a type
+// run at once, such as when its setup calls a downstream service that can
+// only handle a few requests at a time.
+type ConcurrentRegistry struct {
+	Registry Registry
+	// Reject, if set, makes Unmarshal return a *LimitError when a type is
+	// at its limit, instead of waiting for another Unmarshal to finish.
+	Reject bool
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// LimitConcurrency wraps a registry with per-type concurrency limits, set by
+// SetConcurrency.
+func LimitConcurrency(r Registry, reject bool) *ConcurrentRegistry {
+	return &ConcurrentRegistry{
+		Registry: r,
+		Reject:   reject,
+		sems:     make(map[string]chan struct{}),
+	}
+}
+
+// SetConcurrency allows at most n Unmarshals of the type registered as name
+// to run at once. An n of 0 removes the limit. Unmarshals already running are
+// not counted against the new limit. If n is negative, it panics.
+func (c *ConcurrentRegistry) SetConcurrency(name string, n int) {
+	if n < 0 {
+		panic(fmt.Sprintf("typeregistry concurrency of %s must not be negative, got %d", name, n))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n == 0 {
+		delete(c.sems, name)
+		return
+	}
+	c.sems[name] = make(chan struct{}, n)
+}
+
+// Add puts a new type in the registry.
+func (c *ConcurrentRegistry) Add(o interface{}) string {
+	return c.Registry.Add(o)
+}
+
+// New instantiates a type by name.
+func (c *ConcurrentRegistry) New(name string) interface{} {
+	return c.Registry.New(name)
+}
+
+// Marshal encodes a type.
+func (c *ConcurrentRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return c.marshalWith(c, o)
+}
+
+func (c *ConcurrentRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(c.Registry, outer, o)
+}
+
+func (c *ConcurrentRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(c.Registry, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name once fewer than its limit of Unmarshals
+// are running, or returns a *LimitError if Reject is set and the type is at
+// its limit.
+func (c *ConcurrentRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return c.unmarshalWith(c, name, data, setup)
+}
+
+func (c *ConcurrentRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	c.mu.Lock()
+	sem, ok := c.sems[name]
+	c.mu.Unlock()
+	if !ok {
+		return unmarshalVia(c.Registry, outer, name, data, setup)
+	}
+	if c.Reject {
+		select {
+		case sem <- struct{}{}:
+		default:
+			return nil, &LimitError{Name: name, Reason: fmt.Sprintf("%d at once", cap(sem))}
+		}
+	} else {
+		sem <- struct{}{}
+	}
+	defer func() { <-sem }()
+	return unmarshalVia(c.Registry, outer, name, data, setup)
+}
+
+func (c *ConcurrentRegistry) has(name string) bool {
+	return knows(c.Registry, name)
+}
@@ -0,0 +1,125 @@
+package typeregistry
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLimitConcurrency(t *testing.T) {
+	tests := []struct {
+		reject bool
+		errors int
+	}{
+		{reject: false, errors: 0},
+		{reject: true, errors: 2},
+	}
+	for i, test := range tests {
+		r := LimitConcurrency(New(), test.reject)
+		name := r.Add(&nameType{})
+		r.SetConcurrency(name, 1)
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			running int
+			most    int
+			errors  int
+			started = make(chan bool)
+			release = make(chan bool)
+		)
+		setup := func(o interface{}) {
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+			started <- true
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Unmarshal(name, nil, setup)
+		}()
+		<-started
+		var waiting sync.WaitGroup
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			waiting.Add(1)
+			go func() {
+				defer wg.Done()
+				defer waiting.Done()
+				if _, err := r.Unmarshal(name, nil, setup); err != nil {
+					if !IsRetryable(err) {
+						t.Errorf("%d Unmarshal() got %s, want a retryable error", i, err)
+					}
+					mu.Lock()
+					errors++
+					mu.Unlock()
+				}
+			}()
+		}
+		if test.reject {
+			waiting.Wait()
+		} else {
+			release <- true
+			<-started
+			release <- true
+			<-started
+		}
+		release <- true
+		wg.Wait()
+		if most != 1 {
+			t.Errorf("%d Unmarshal() ran %d at once, want 1", i, most)
+		}
+		if errors != test.errors {
+			t.Errorf("%d Unmarshal() got %d errors, want %d", i, errors, test.errors)
+		}
+	}
+}
+
+func TestLimitConcurrency_unlimited(t *testing.T) {
+	r := LimitConcurrency(New(), true)
+	name := r.Add(&nameType{})
+	r.SetConcurrency(name, 1)
+	r.SetConcurrency(name, 0)
+
+	started := make(chan bool)
+	release := make(chan bool)
+	done := make(chan error)
+	setup := func(o interface{}) {
+		started <- true
+		<-release
+	}
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := r.Unmarshal(name, nil, setup)
+			done <- err
+		}()
+	}
+	<-started
+	<-started
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		r.SetConcurrency(name, -1)
+	}()
+	if want := "typeregistry concurrency of *typeregistry.nameType must not be negative, got -1"; paniced != want {
+		t.Errorf("SetConcurrency() got panic %q, want %q", paniced, want)
+	}
+}
@@ -0,0 +1,161 @@
+package typeregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ConfigEntry is one registration in a saved configuration.
+type ConfigEntry struct {
+	// Name is the registered name.
+	Name string `json:"name"`
+	// Type identifies the Go type by its package path and name.
+	Type string `json:"type"`
+	// Version is the module version recorded when the type was added, if
+	// any.
+	Version string `json:"version,omitempty"`
+	// Retention holds the retention hints of the type, if it has any. They
+	// are for the storage layers of the process that loads the
+	// configuration, and are not needed by LoadConfig.
+	Retention *Retention `json:"retention,omitempty"`
+	// Aliases are the old names of the type, saved by an AliasRegistry.
+	Aliases []string `json:"aliases,omitempty"`
+	// Encoding is the encoding chosen for the type, saved by an
+	// EncodedRegistry.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// SaveConfig encodes the registry's registrations as JSON, so that another
+// process can rebuild the same registry with LoadConfig. Entries are sorted
+// by name.
+func (r TypeRegistry) SaveConfig() ([]byte, error) {
+	return json.Marshal(r.configEntries())
+}
+
+// configEntries returns an entry for each registered name, sorted by name.
+func (r TypeRegistry) configEntries() []ConfigEntry {
+	names := r.Names()
+	entries := make([]ConfigEntry, 0, len(names))
+	for _, name := range names {
+		e := ConfigEntry{
+			Name:    name,
+			Type:    typeID(r[name]),
+			Version: moduleVersionOf(r[name]),
+		}
+		if retention := r.Retention(name); retention != (Retention{}) {
+			e.Retention = &retention
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// SaveConfig encodes the registry's registrations and aliases as JSON, so
+// that another process can rebuild the same registry with LoadConfig.
+func (a *AliasRegistry) SaveConfig() ([]byte, error) {
+	olds := make(map[string][]string)
+	for old, canonical := range a.Aliases() {
+		olds[canonical] = append(olds[canonical], old)
+	}
+	entries := a.TypeRegistry.configEntries()
+	for i, e := range entries {
+		sort.Strings(olds[e.Name])
+		entries[i].Aliases = olds[e.Name]
+	}
+	return json.Marshal(entries)
+}
+
+// SaveConfig encodes the registry's registrations and chosen encodings as
+// JSON, so that another process can rebuild the same registry with
+// LoadConfig.
+func (r *EncodedRegistry) SaveConfig() ([]byte, error) {
+	entries := r.TypeRegistry.configEntries()
+	for i, e := range entries {
+		if enc, ok := r.encoding(e.Name); ok {
+			entries[i].Encoding = enc.String()
+		}
+	}
+	return json.Marshal(entries)
+}
+
+// LoadConfig builds a registry from a configuration saved by SaveConfig. Since
+// Go cannot find types by name, the catalogue must hold a value of every type
+// in the configuration. It returns an error if one is missing. Aliases and
+// encodings are ignored; load them with AliasRegistry.LoadConfig or
+// EncodedRegistry.LoadConfig.
+func LoadConfig(data []byte, catalogue ...interface{}) (TypeRegistry, error) {
+	r := New()
+	if _, err := r.loadConfig(data, catalogue); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadConfig adds the registrations and aliases of a configuration saved by
+// SaveConfig to the registry, as the package LoadConfig does.
+func (a *AliasRegistry) LoadConfig(data []byte, catalogue ...interface{}) error {
+	entries, err := a.TypeRegistry.loadConfig(data, catalogue)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		for _, old := range e.Aliases {
+			if err := a.Alias(old, e.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadConfig adds the registrations and encodings of a configuration saved by
+// SaveConfig to the registry, as the package LoadConfig does.
+func (r *EncodedRegistry) LoadConfig(data []byte, catalogue ...interface{}) error {
+	entries, err := r.TypeRegistry.loadConfig(data, catalogue)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Encoding == "" {
+			continue
+		}
+		enc, ok := parseEncoding(e.Encoding)
+		if !ok {
+			return fmt.Errorf("typeregistry has no encoding %#v for %#v", e.Encoding, e.Name)
+		}
+		if err := r.setEncoding(e.Name, enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfig adds each entry of data to the registry with AddNamed, using the
+// value in catalogue of the entry's type, and returns the entries.
+func (r TypeRegistry) loadConfig(data []byte, catalogue []interface{}) ([]ConfigEntry, error) {
+	var entries []ConfigEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(catalogue))
+	for _, o := range catalogue {
+		values[typeID(reflect.TypeOf(o))] = o
+	}
+	for _, e := range entries {
+		o, ok := values[e.Type]
+		if !ok {
+			return nil, fmt.Errorf("typeregistry catalogue has no type %s for %#v", e.Type, e.Name)
+		}
+		if err := r.AddNamed(e.Name, o); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// typeID identifies a type across processes by its package path and name.
+func typeID(t reflect.Type) string {
+	return identityOf(t).ID()
+}
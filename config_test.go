@@ -0,0 +1,118 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_SaveConfig(t *testing.T) {
+	defer withBuildInfo(nil)()
+	r := New()
+	r.Add(&nameType{})
+	r.Add(nothingType{})
+	r.Add(0)
+	r["legacy"] = r["*typeregistry.nameType"]
+
+	data, err := r.SaveConfig()
+	if err != nil {
+		t.Fatalf("SaveConfig() wants no error, got: %s", err)
+	}
+	want := `[{"name":"*typeregistry.nameType","type":"github.com/rcarver/typeregistry *typeregistry.nameType"},` +
+		`{"name":"int","type":"int"},` +
+		`{"name":"legacy","type":"github.com/rcarver/typeregistry *typeregistry.nameType"},` +
+		`{"name":"typeregistry.nothingType","type":"github.com/rcarver/typeregistry typeregistry.nothingType"}]`
+	if string(data) != want {
+		t.Errorf("SaveConfig() got %s, want %s", data, want)
+	}
+	for i := 0; i < 10; i++ {
+		if again, _ := r.SaveConfig(); string(again) != string(data) {
+			t.Fatalf("%d SaveConfig() got %s, want %s", i, again, data)
+		}
+	}
+
+	got, err := LoadConfig(data, 0, nothingType{}, &nameType{}, marshalType{})
+	if err != nil {
+		t.Fatalf("LoadConfig() wants no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("LoadConfig() got %#v, want %#v", got, r)
+	}
+
+	if _, err := LoadConfig(data, 0, nothingType{}); err == nil {
+		t.Errorf("LoadConfig() with a missing type wants error, got none")
+	}
+	if _, err := LoadConfig([]byte("{")); err == nil {
+		t.Errorf("LoadConfig() of bad data wants error, got none")
+	}
+}
+
+func TestAliasRegistry_SaveConfig(t *testing.T) {
+	defer withBuildInfo(nil)()
+	r := WithAliases(New())
+	name := r.Add(&nameType{})
+	r.Add(nothingType{})
+	if err := r.Alias("old.nameType", name); err != nil {
+		t.Fatalf("Alias() wants no error, got: %s", err)
+	}
+	if err := r.Alias("older.nameType", name); err != nil {
+		t.Fatalf("Alias() wants no error, got: %s", err)
+	}
+
+	data, err := r.SaveConfig()
+	if err != nil {
+		t.Fatalf("SaveConfig() wants no error, got: %s", err)
+	}
+	want := `[{"name":"*typeregistry.nameType","type":"github.com/rcarver/typeregistry *typeregistry.nameType","aliases":["old.nameType","older.nameType"]},` +
+		`{"name":"typeregistry.nothingType","type":"github.com/rcarver/typeregistry typeregistry.nothingType"}]`
+	if string(data) != want {
+		t.Errorf("SaveConfig() got %s, want %s", data, want)
+	}
+
+	got := WithAliases(New())
+	if err := got.LoadConfig(data, nothingType{}, &nameType{}); err != nil {
+		t.Fatalf("LoadConfig() wants no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(got.TypeRegistry, r.TypeRegistry) {
+		t.Errorf("LoadConfig() got %#v, want %#v", got.TypeRegistry, r.TypeRegistry)
+	}
+	if !reflect.DeepEqual(got.Aliases(), r.Aliases()) {
+		t.Errorf("LoadConfig() aliases got %#v, want %#v", got.Aliases(), r.Aliases())
+	}
+
+	clash := WithAliases(New())
+	clash.TypeRegistry.AddNamed("old.nameType", nothingType{})
+	if err := clash.LoadConfig(data, nothingType{}, &nameType{}); err == nil {
+		t.Errorf("LoadConfig() of an alias of a registered name wants error, got none")
+	}
+}
+
+func TestEncodedRegistry_SaveConfig(t *testing.T) {
+	defer withBuildInfo(nil)()
+	r := WithEncodings(New())
+	name := r.Add(ifaceType{})
+	r.Add(nothingType{})
+	r.SetEncoding(name, EncodingText)
+
+	data, err := r.SaveConfig()
+	if err != nil {
+		t.Fatalf("SaveConfig() wants no error, got: %s", err)
+	}
+	want := `[{"name":"typeregistry.ifaceType","type":"github.com/rcarver/typeregistry typeregistry.ifaceType","encoding":"text"},` +
+		`{"name":"typeregistry.nothingType","type":"github.com/rcarver/typeregistry typeregistry.nothingType"}]`
+	if string(data) != want {
+		t.Errorf("SaveConfig() got %s, want %s", data, want)
+	}
+
+	got := WithEncodings(New())
+	if err := got.LoadConfig(data, nothingType{}, ifaceType{}); err != nil {
+		t.Fatalf("LoadConfig() wants no error, got: %s", err)
+	}
+	if e, ok := got.encoding(name); !ok || e != EncodingText {
+		t.Errorf("LoadConfig() encoding got %s, want %s", e, EncodingText)
+	}
+
+	bad := []byte(`[{"name":"typeregistry.nothingType","type":"github.com/rcarver/typeregistry typeregistry.nothingType","encoding":"text"}]`)
+	if err := WithEncodings(New()).LoadConfig(bad, nothingType{}); err == nil {
+		t.Errorf("LoadConfig() of an unsupported encoding wants error, got none")
+	}
+}
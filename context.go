@@ -0,0 +1,29 @@
+package typeregistry
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries r, so that middleware can
+// choose the registry for a request, such as by tenant or API version.
+func NewContext(ctx context.Context, r Registry) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the registry carried by ctx, if any.
+func FromContext(ctx context.Context) (Registry, bool) {
+	r, ok := ctx.Value(contextKey{}).(Registry)
+	return r, ok
+}
+
+// WithOverlay returns a copy of ctx that carries an Overlay of overrides on
+// the registry carried by ctx, so that one request can use its own versions
+// of some types. If ctx carries no registry, the overrides are overlaid on an
+// empty one.
+func WithOverlay(ctx context.Context, overrides TypeRegistry) context.Context {
+	r, ok := FromContext(ctx)
+	if !ok {
+		r = New()
+	}
+	return NewContext(ctx, Overlay(r, overrides))
+}
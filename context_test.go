@@ -0,0 +1,51 @@
+package typeregistry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestNewContext(t *testing.T) {
+	if r, ok := FromContext(context.Background()); ok || r != nil {
+		t.Errorf("FromContext() of an empty context got %#v, %t, want nil, false", r, ok)
+	}
+	r := New()
+	r.Add(&nameType{})
+	got, ok := FromContext(NewContext(context.Background(), r))
+	if !ok {
+		t.Fatalf("FromContext() got false, want true")
+	}
+	if _, ok := got.(TypeRegistry)["*typeregistry.nameType"]; !ok {
+		t.Errorf("FromContext() got %#v, want the registry", got)
+	}
+}
+
+func TestWithOverlay(t *testing.T) {
+	r := New()
+	r.Add(&unmarshalType{})
+	overrides := New()
+	overrides.AddNamed("*typeregistry.unmarshalType", &unmarshalV2Type{})
+
+	ctx := WithOverlay(NewContext(context.Background(), r), overrides)
+	got, ok := FromContext(ctx)
+	if !ok {
+		t.Fatalf("FromContext() got false, want true")
+	}
+	if o := got.New("*typeregistry.unmarshalType"); reflect.TypeOf(o) != reflect.TypeOf(&unmarshalV2Type{}) {
+		t.Errorf("New() got %T, want the override", o)
+	}
+	if o := r.New("*typeregistry.unmarshalType"); reflect.TypeOf(o) != reflect.TypeOf(&unmarshalType{}) {
+		t.Errorf("New() of the parent got %T, want it unchanged", o)
+	}
+
+	got, _ = FromContext(WithOverlay(context.Background(), overrides))
+	if o := got.New("*typeregistry.unmarshalType"); reflect.TypeOf(o) != reflect.TypeOf(&unmarshalV2Type{}) {
+		t.Errorf("New() without a parent got %T, want the override", o)
+	}
+
+	w := r.With(overrides).With(New())
+	if o := w.New("*typeregistry.unmarshalType"); reflect.TypeOf(o) != reflect.TypeOf(&unmarshalV2Type{}) {
+		t.Errorf("With() New() got %T, want the override", o)
+	}
+}
@@ -0,0 +1,125 @@
+package typeregistry
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ConvertFunc converts a value of one type to another.
+type ConvertFunc func(interface{}) (interface{}, error)
+
+// ConvertRegistry is a Registry in which the type that is written can differ
+// from the type that is read, such as a rich domain object that is written
+// as a plain data transfer object, while the wire name stays the same.
+type ConvertRegistry struct {
+	TypeRegistry TypeRegistry
+
+	mu      sync.RWMutex
+	writers map[reflect.Type]ConvertFunc
+	readers map[string]ConvertFunc
+}
+
+// WithConverters wraps a registry so that types can be registered with
+// AddDual and Convert.
+func WithConverters(r TypeRegistry) *ConvertRegistry {
+	return &ConvertRegistry{
+		TypeRegistry: r,
+		writers:      make(map[reflect.Type]ConvertFunc),
+		readers:      make(map[string]ConvertFunc),
+	}
+}
+
+// AddDual registers read, and arranges for Marshal to convert values of
+// write's type with to before encoding them, so that they are written under
+// read's name and encoding. Unmarshal returns values of read's type. It
+// returns the name that read was registered as.
+func (c *ConvertRegistry) AddDual(write, read interface{}, to ConvertFunc) string {
+	name := c.Add(read)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writers[reflect.TypeOf(write)] = to
+	return name
+}
+
+// Convert registers dto as the wire form of domain. Marshal converts domain
+// values with toDTO before encoding them, and Unmarshal converts the decoded
+// dto with fromDTO, so that callers only see domain values. New and the
+// setup function passed to Unmarshal see the dto. It returns the name that
+// dto was registered as.
+func (c *ConvertRegistry) Convert(domain, dto interface{}, toDTO, fromDTO ConvertFunc) string {
+	name := c.AddDual(domain, dto, toDTO)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readers[name] = fromDTO
+	return name
+}
+
+// Add puts a new type in the registry.
+func (c *ConvertRegistry) Add(o interface{}) string {
+	return c.TypeRegistry.Add(o)
+}
+
+// New instantiates a type by name. If the name is unknown, it panics.
+func (c *ConvertRegistry) New(name string) interface{} {
+	return c.TypeRegistry.New(name)
+}
+
+// Marshal encodes a type, first converting it if it was registered as the
+// write type of AddDual or the domain type of Convert.
+func (c *ConvertRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return c.marshalWith(c, o)
+}
+
+func (c *ConvertRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	v, err := c.write(o)
+	if err != nil {
+		return c.TypeRegistry.name(o), nil, err
+	}
+	return c.TypeRegistry.marshalWith(outer, v)
+}
+
+func (c *ConvertRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	v, err := c.write(o)
+	if err != nil {
+		return c.TypeRegistry.name(o), dst, err
+	}
+	return c.TypeRegistry.appendWith(outer, dst, v)
+}
+
+// write converts o if it was registered as a write type.
+func (c *ConvertRegistry) write(o interface{}) (interface{}, error) {
+	if o == nil {
+		return o, nil
+	}
+	c.mu.RLock()
+	to, ok := c.writers[reflect.TypeOf(o)]
+	c.mu.RUnlock()
+	if !ok {
+		return o, nil
+	}
+	return to(o)
+}
+
+// Unmarshal decodes a type by name, then converts it if it was registered as
+// the dto type of Convert.
+func (c *ConvertRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return c.unmarshalWith(c, name, data, setup)
+}
+
+func (c *ConvertRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	o, err := c.TypeRegistry.unmarshalWith(outer, name, data, setup)
+	if err != nil {
+		return o, err
+	}
+	c.mu.RLock()
+	from, ok := c.readers[name]
+	c.mu.RUnlock()
+	if !ok {
+		return o, nil
+	}
+	return from(o)
+}
+
+func (c *ConvertRegistry) has(name string) bool {
+	return c.TypeRegistry.has(name)
+}
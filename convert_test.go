@@ -0,0 +1,110 @@
+package typeregistry
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type accountType struct {
+	ID      string
+	balance int
+}
+
+type accountDTO struct {
+	JSON
+	ID      string
+	Balance int
+}
+
+func toAccountDTO(o interface{}) (interface{}, error) {
+	a := o.(*accountType)
+	if a.ID == "" {
+		return nil, errors.New("no id")
+	}
+	return &accountDTO{ID: a.ID, Balance: a.balance}, nil
+}
+
+func TestConvertRegistry_AddDual(t *testing.T) {
+	r := WithConverters(New())
+	name := r.AddDual(&accountType{}, &accountDTO{}, toAccountDTO)
+	if name != "*typeregistry.accountDTO" {
+		t.Errorf("AddDual() got %s, want *typeregistry.accountDTO", name)
+	}
+
+	mname, data, err := r.Marshal(&accountType{ID: "a", balance: 5})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if mname != name {
+		t.Errorf("Marshal() name got %s, want %s", mname, name)
+	}
+	if want := `{"ID":"a","Balance":5}`; string(data) != want {
+		t.Errorf("Marshal() got %s, want %s", data, want)
+	}
+	got, err := r.Unmarshal(mname, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&accountDTO{ID: "a", Balance: 5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+
+	if _, _, err := r.Marshal(&accountType{}); err == nil {
+		t.Errorf("Marshal() with a failing conversion wants error, got none")
+	}
+	if mname, _, _ := r.Marshal(&accountDTO{}); mname != name {
+		t.Errorf("Marshal() of the read type got %s, want %s", mname, name)
+	}
+}
+
+func fromAccountDTO(o interface{}) (interface{}, error) {
+	d := o.(*accountDTO)
+	if d.Balance < 0 {
+		return nil, errors.New("negative balance")
+	}
+	return &accountType{ID: d.ID, balance: d.Balance}, nil
+}
+
+func TestConvertRegistry_Convert(t *testing.T) {
+	r := WithConverters(New())
+	name := r.Convert(&accountType{}, &accountDTO{}, toAccountDTO, fromAccountDTO)
+
+	tests := []struct {
+		data string
+		want interface{}
+		err  bool
+	}{
+		{data: `{"ID":"a","Balance":5}`, want: &accountType{ID: "a", balance: 5}},
+		{data: `{"ID":"a","Balance":-1}`, err: true},
+		{data: `{`, err: true},
+	}
+	for i, test := range tests {
+		got, err := r.Unmarshal(name, []byte(test.data), NoSetup)
+		if test.err {
+			if err == nil {
+				t.Errorf("%d Unmarshal() wants error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.want)
+		}
+	}
+
+	o := &accountType{ID: "b", balance: 2}
+	mname, data, err := r.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	got, err := r.Unmarshal(mname, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("Unmarshal() round trip got %#v, want %#v", got, o)
+	}
+}
@@ -0,0 +1,125 @@
+package typeregistry
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DiffValues compares two values of the same registered type and returns a
+// description of their differences, one exported field per line, or "" if
+// they are equal. It is useful in tests and in audit logs showing what changed
+// between two versions of a stored object.
+func (r TypeRegistry) DiffValues(a, b interface{}) (string, error) {
+	if a == nil || b == nil {
+		return "", fmt.Errorf("typeregistry cannot diff nil")
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return "", fmt.Errorf("typeregistry cannot diff %s and %s", ta, tb)
+	}
+	if !r.hasType(ta) {
+		return "", ErrUnknownType{Name: r.name(a)}
+	}
+	defer trace("Diff", ta)()
+	d := &differ{visited: make(map[[2]uintptr]bool)}
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.buf.String(), nil
+}
+
+type differ struct {
+	buf     bytes.Buffer
+	visited map[[2]uintptr]bool
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.report(path, a, b)
+			}
+			return
+		}
+		key := [2]uintptr{a.Pointer(), b.Pointer()}
+		if d.visited[key] {
+			return
+		}
+		d.visited[key] = true
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() || a.Elem().Type() != b.Elem().Type() {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				d.report(path, a, b)
+			}
+			return
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			d.diff(join(path, f.Name), a.Field(i), b.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				d.report(p, reflect.Value{}, b.Index(i))
+			case i >= b.Len():
+				d.report(p, a.Index(i), reflect.Value{})
+			default:
+				d.diff(p, a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Map:
+		keys := a.MapKeys()
+		for _, k := range b.MapKeys() {
+			if !a.MapIndex(k).IsValid() {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%#v", keys[i].Interface()) < fmt.Sprintf("%#v", keys[j].Interface())
+		})
+		for _, k := range keys {
+			p := fmt.Sprintf("%s[%#v]", path, k.Interface())
+			va, vb := a.MapIndex(k), b.MapIndex(k)
+			if !va.IsValid() || !vb.IsValid() {
+				d.report(p, va, vb)
+				continue
+			}
+			d.diff(p, va, vb)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.report(path, a, b)
+		}
+	}
+}
+
+func (d *differ) report(path string, a, b reflect.Value) {
+	if path != "" {
+		d.buf.WriteString(path)
+		d.buf.WriteString(": ")
+	}
+	fmt.Fprintf(&d.buf, "%s => %s\n", show(a), show(b))
+}
+
+func show(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<none>"
+	}
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,106 @@
+package typeregistry
+
+import "testing"
+
+type diffType struct {
+	Name   string
+	Tags   []string
+	Counts map[string]int
+	Child  *diffType
+	Any    interface{}
+	hidden int
+}
+
+func TestTypeRegistry_DiffValues(t *testing.T) {
+	loop := &diffType{Name: "loop"}
+	loop.Child = loop
+
+	tests := []struct {
+		a, b interface{}
+		want string
+	}{
+		{
+			a:    &diffType{Name: "a", hidden: 1},
+			b:    &diffType{Name: "a", hidden: 2},
+			want: "",
+		},
+		{
+			a:    &diffType{Name: "a"},
+			b:    &diffType{Name: "b"},
+			want: "Name: \"a\" => \"b\"\n",
+		},
+		{
+			a:    &diffType{Tags: []string{"x", "y"}},
+			b:    &diffType{Tags: []string{"x", "z", "w"}},
+			want: "Tags[1]: \"y\" => \"z\"\nTags[2]: <none> => \"w\"\n",
+		},
+		{
+			a:    &diffType{Counts: map[string]int{"a": 1, "b": 2}},
+			b:    &diffType{Counts: map[string]int{"b": 3, "c": 4}},
+			want: "Counts[\"a\"]: 1 => <none>\nCounts[\"b\"]: 2 => 3\nCounts[\"c\"]: <none> => 4\n",
+		},
+		{
+			a:    &diffType{Child: &diffType{Name: "x"}},
+			b:    &diffType{Child: &diffType{Name: "y"}},
+			want: "Child.Name: \"x\" => \"y\"\n",
+		},
+		{
+			a:    &diffType{Any: 1},
+			b:    &diffType{Any: "1"},
+			want: "Any: 1 => \"1\"\n",
+		},
+		{
+			a:    loop,
+			b:    loop,
+			want: "",
+		},
+	}
+	r := New()
+	r.Add(&diffType{})
+	for i, test := range tests {
+		got, err := r.DiffValues(test.a, test.b)
+		if err != nil {
+			t.Errorf("%d DiffValues() wants no error, got: %s", i, err)
+		}
+		if got != test.want {
+			t.Errorf("%d DiffValues() got %q, want %q", i, got, test.want)
+		}
+	}
+
+	errs := []struct {
+		a, b interface{}
+	}{
+		{a: nil, b: &diffType{}},
+		{a: &diffType{}, b: diffType{}},
+		{a: nameType{}, b: nameType{}},
+	}
+	for i, test := range errs {
+		if _, err := r.DiffValues(test.a, test.b); err == nil {
+			t.Errorf("%d DiffValues(%#v, %#v) wants error, got none", i, test.a, test.b)
+		}
+	}
+}
+
+func TestTypeRegistry_DiffValues_named(t *testing.T) {
+	r := New()
+	if err := r.AddNamed("diff", &diffType{}); err != nil {
+		t.Fatalf("AddNamed() wants no error, got: %s", err)
+	}
+	r.AddNames(nameType{}, "name.a", "name.b")
+	tests := []struct {
+		a, b interface{}
+		want string
+	}{
+		{a: &diffType{Name: "a"}, b: &diffType{Name: "b"}, want: "Name: \"a\" => \"b\"\n"},
+		{a: nameType{}, b: nameType{}, want: ""},
+	}
+	for i, test := range tests {
+		got, err := r.DiffValues(test.a, test.b)
+		if err != nil {
+			t.Errorf("%d DiffValues() wants no error, got: %s", i, err)
+		}
+		if got != test.want {
+			t.Errorf("%d DiffValues() got %q, want %q", i, got, test.want)
+		}
+	}
+}
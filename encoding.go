@@ -0,0 +1,356 @@
+package typeregistry
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Encoding identifies one of the ways the registry can encode a type. A type
+// may support several, such as a Marshaler that is also an Enum. Marshal and
+// Unmarshal each use the first encoding the type supports, in the order the
+// encodings are declared here. Use EncodedRegistry to choose a different one.
+type Encoding int
+
+const (
+	// EncodingRegistry uses RegistryMarshaler and RegistryUnmarshaler.
+	EncodingRegistry Encoding = iota + 1
+	// EncodingCustom uses Marshaler and Unmarshaler.
+	EncodingCustom
+	// EncodingEnum encodes an Enum as its string value.
+	EncodingEnum
+	// EncodingJSON encodes a struct embedding JSON with encoding/json.
+	EncodingJSON
+	// EncodingStdlib uses the built-in encodings of the types added by
+	// AddStdlib.
+	EncodingStdlib
+	// EncodingBinary uses encoding.BinaryMarshaler and
+	// encoding.BinaryUnmarshaler.
+	EncodingBinary
+	// EncodingText uses encoding.TextMarshaler and encoding.TextUnmarshaler.
+	EncodingText
+	// EncodingJSONMarshaler uses json.Marshaler and json.Unmarshaler.
+	EncodingJSONMarshaler
+)
+
+// encodings is every Encoding, in order of precedence.
+var encodings = []Encoding{
+	EncodingRegistry, EncodingCustom, EncodingEnum, EncodingJSON, EncodingStdlib,
+	EncodingBinary, EncodingText, EncodingJSONMarshaler,
+}
+
+var encodingNames = map[Encoding]string{
+	EncodingRegistry:      "registry",
+	EncodingCustom:        "custom",
+	EncodingEnum:          "enum",
+	EncodingJSON:          "json",
+	EncodingStdlib:        "stdlib",
+	EncodingBinary:        "binary",
+	EncodingText:          "text",
+	EncodingJSONMarshaler: "jsonmarshaler",
+}
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textMarshalerType     = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType   = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// stdInterfaces holds the marshaler and unmarshaler interfaces of each of the
+// encodings that fall back to standard library interfaces.
+var stdInterfaces = map[Encoding][2]reflect.Type{
+	EncodingBinary:        {binaryMarshalerType, binaryUnmarshalerType},
+	EncodingText:          {textMarshalerType, textUnmarshalerType},
+	EncodingJSONMarshaler: {jsonMarshalerType, jsonUnmarshalerType},
+}
+
+func (e Encoding) String() string {
+	if s, ok := encodingNames[e]; ok {
+		return s
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// parseEncoding returns the Encoding whose String is s, or false if there is
+// none.
+func parseEncoding(s string) (Encoding, bool) {
+	for e, name := range encodingNames {
+		if name == s {
+			return e, true
+		}
+	}
+	return 0, false
+}
+
+// canMarshal reports whether o can be marshaled with e.
+func (e Encoding) canMarshal(o interface{}) bool {
+	switch e {
+	case EncodingRegistry:
+		_, ok := o.(RegistryMarshaler)
+		return ok
+	case EncodingCustom:
+		_, ok := o.(Marshaler)
+		return ok
+	case EncodingEnum:
+		_, ok := o.(Enum)
+		return ok
+	case EncodingJSON:
+		_, ok := o.(jsonSelf)
+		return ok
+	case EncodingStdlib:
+		return hasStd(reflect.TypeOf(o))
+	case EncodingBinary, EncodingText, EncodingJSONMarshaler:
+		return reflect.TypeOf(o).Implements(stdInterfaces[e][0])
+	}
+	return false
+}
+
+// canUnmarshal reports whether instance can be unmarshaled with e.
+func (e Encoding) canUnmarshal(instance interface{}) bool {
+	switch e {
+	case EncodingRegistry:
+		_, ok := instance.(RegistryUnmarshaler)
+		return ok
+	case EncodingCustom:
+		_, ok := instance.(Unmarshaler)
+		return ok
+	case EncodingEnum, EncodingJSON, EncodingStdlib:
+		return e.canMarshal(instance)
+	case EncodingBinary, EncodingText, EncodingJSONMarshaler:
+		return canUnmarshalStd(reflect.TypeOf(instance), e)
+	}
+	return false
+}
+
+// canUnmarshalStd reports whether t, or a pointer to it, implements the
+// unmarshaler interface of e, one of the encodings that fall back to standard
+// library interfaces.
+func canUnmarshalStd(t reflect.Type, e Encoding) bool {
+	u := stdInterfaces[e][1]
+	return t.Implements(u) || t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(u)
+}
+
+// marshalEncoding returns the first encoding o can be marshaled with, or 0 if
+// it has none.
+func marshalEncoding(o interface{}) Encoding {
+	for _, e := range encodings {
+		if e.canMarshal(o) {
+			return e
+		}
+	}
+	return 0
+}
+
+// unmarshalEncoding returns the first encoding instance can be unmarshaled
+// with, or 0 if it has none.
+func unmarshalEncoding(instance interface{}) Encoding {
+	for _, e := range encodings {
+		if e.canUnmarshal(instance) {
+			return e
+		}
+	}
+	return 0
+}
+
+// marshalAs encodes o with e. An Encoding of 0 encodes nothing. A
+// RegistryMarshaler is given outer to marshal its nested values.
+func (r TypeRegistry) marshalAs(outer Registry, o interface{}, e Encoding) ([]byte, error) {
+	if e != 0 && !e.canMarshal(o) {
+		return nil, fmt.Errorf("typeregistry cannot marshal %s with encoding %s", r.name(o), e)
+	}
+	switch e {
+	case EncodingRegistry:
+		return o.(RegistryMarshaler).MarshalWithRegistry(outer)
+	case EncodingCustom:
+		return o.(Marshaler).Marshal()
+	case EncodingEnum:
+		return marshalEnum(o.(Enum))
+	case EncodingJSON:
+		return marshalJSON(o, nil)
+	case EncodingStdlib:
+		data, _, err := marshalStd(o)
+		return data, err
+	case EncodingBinary:
+		return o.(encoding.BinaryMarshaler).MarshalBinary()
+	case EncodingText:
+		return o.(encoding.TextMarshaler).MarshalText()
+	case EncodingJSONMarshaler:
+		return o.(json.Marshaler).MarshalJSON()
+	}
+	return nil, nil
+}
+
+// unmarshalAs decodes data into instance with e, returning the result. An
+// Encoding of 0 leaves instance as it is. A RegistryUnmarshaler is given outer
+// to unmarshal its nested values.
+func (r TypeRegistry) unmarshalAs(outer Registry, instance interface{}, data []byte, e Encoding) (interface{}, error) {
+	if e != 0 && !e.canUnmarshal(instance) {
+		return instance, fmt.Errorf("typeregistry cannot unmarshal %s with encoding %s", r.name(instance), e)
+	}
+	if e != EncodingStdlib {
+		defer trace("Unmarshal", reflect.TypeOf(instance))()
+	}
+	switch e {
+	case EncodingRegistry:
+		return instance, instance.(RegistryUnmarshaler).UnmarshalWithRegistry(outer, data)
+	case EncodingCustom:
+		return instance, instance.(Unmarshaler).Unmarshal(data)
+	case EncodingEnum:
+		return unmarshalEnum(instance.(Enum), data)
+	case EncodingJSON:
+		return unmarshalJSON(instance, data, nil)
+	case EncodingStdlib:
+		o, _, err := unmarshalStd(instance, data)
+		return o, err
+	case EncodingBinary, EncodingText, EncodingJSONMarshaler:
+		return unmarshalInterface(instance, data, e)
+	}
+	return instance, nil
+}
+
+// unmarshalInterface decodes data into instance, which may be a pointer or a
+// value, with the unmarshaler interface of e, returning the result. Empty
+// data leaves instance as it is.
+func unmarshalInterface(instance interface{}, data []byte, e Encoding) (interface{}, error) {
+	if len(data) == 0 {
+		return instance, nil
+	}
+	ptr := reflect.ValueOf(instance)
+	if ptr.Kind() != reflect.Ptr {
+		ptr = reflect.New(reflect.TypeOf(instance))
+		ptr.Elem().Set(reflect.ValueOf(instance))
+	}
+	var err error
+	switch p := ptr.Interface(); e {
+	case EncodingBinary:
+		err = p.(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+	case EncodingText:
+		err = p.(encoding.TextUnmarshaler).UnmarshalText(data)
+	case EncodingJSONMarshaler:
+		err = p.(json.Unmarshaler).UnmarshalJSON(data)
+	}
+	if reflect.TypeOf(instance).Kind() == reflect.Ptr {
+		return instance, err
+	}
+	return ptr.Elem().Interface(), err
+}
+
+// EncodedRegistry is a Registry that uses a chosen Encoding for some types,
+// instead of the first one they support.
+type EncodedRegistry struct {
+	TypeRegistry TypeRegistry
+
+	mu        sync.RWMutex
+	encodings map[string]Encoding
+}
+
+// WithEncodings wraps a registry so that the encoding of each type can be
+// chosen with SetEncoding.
+func WithEncodings(r TypeRegistry) *EncodedRegistry {
+	return &EncodedRegistry{TypeRegistry: r, encodings: make(map[string]Encoding)}
+}
+
+// SetEncoding makes Marshal and Unmarshal use e for the type registered as
+// name. If the name is unknown, or the type supports e for neither marshaling
+// nor unmarshaling, it panics.
+func (r *EncodedRegistry) SetEncoding(name string, e Encoding) {
+	if err := r.setEncoding(name, e); err != nil {
+		panic(err.Error())
+	}
+}
+
+// setEncoding is SetEncoding, returning an error if the type does not support
+// e. If the name is unknown, it panics.
+func (r *EncodedRegistry) setEncoding(name string, e Encoding) error {
+	o := r.TypeRegistry.New(name)
+	if !e.canMarshal(o) && !e.canUnmarshal(o) {
+		return fmt.Errorf("typeregistry %s does not support encoding %s", name, e)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encodings[name] = e
+	return nil
+}
+
+// Add puts a new type in the registry.
+func (r *EncodedRegistry) Add(o interface{}) string {
+	return r.TypeRegistry.Add(o)
+}
+
+// New instantiates a type by name. If the name is unknown, it panics.
+func (r *EncodedRegistry) New(name string) interface{} {
+	return r.TypeRegistry.New(name)
+}
+
+// Marshal encodes a type with its chosen encoding, if it has one. It returns
+// an error if the type cannot be marshaled with that encoding.
+func (r *EncodedRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return r.marshalWith(r, o)
+}
+
+func (r *EncodedRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	if u, ok := o.(*Unknown); ok {
+		return u.Name, u.Data, nil
+	}
+	name, err := r.TypeRegistry.marshalName(o)
+	if err != nil {
+		return name, nil, err
+	}
+	e, ok := r.encoding(name)
+	if !ok {
+		return r.TypeRegistry.marshalWith(outer, o)
+	}
+	if err := normalize(o); err != nil {
+		return name, nil, err
+	}
+	data, err := r.TypeRegistry.marshalAs(outer, o, e)
+	if len(data) == 0 {
+		data = nil
+	}
+	return name, data, err
+}
+
+func (r *EncodedRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	if name, err := r.TypeRegistry.marshalName(o); err == nil {
+		if _, ok := r.encoding(name); ok {
+			return appendMarshaled(r, outer, dst, o)
+		}
+	}
+	return r.TypeRegistry.appendWith(outer, dst, o)
+}
+
+// Unmarshal decodes a type by name with its chosen encoding, if it has one.
+// It returns an error if the type cannot be unmarshaled with that encoding.
+func (r *EncodedRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return r.unmarshalWith(r, name, data, setup)
+}
+
+func (r *EncodedRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	e, ok := r.encoding(name)
+	if !ok {
+		return r.TypeRegistry.unmarshalWith(outer, name, data, setup)
+	}
+	if len(data) == 0 {
+		data = nil
+	}
+	instance := r.New(name)
+	if setup != nil {
+		setup(instance)
+	}
+	return r.TypeRegistry.unmarshalAs(outer, instance, data, e)
+}
+
+func (r *EncodedRegistry) has(name string) bool {
+	return r.TypeRegistry.has(name)
+}
+
+func (r *EncodedRegistry) encoding(name string) (Encoding, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.encodings[name]
+	return e, ok
+}
@@ -0,0 +1,176 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type bothType string
+
+func (bothType) EnumValues() []string {
+	return []string{"a", "b"}
+}
+
+func (b *bothType) Marshal() ([]byte, error) {
+	return []byte("custom:" + string(*b)), nil
+}
+
+func (b *bothType) Unmarshal(data []byte) error {
+	*b = bothType("custom")
+	return nil
+}
+
+func TestEncoding(t *testing.T) {
+	a := bothType("a")
+	tests := []struct {
+		encoding Encoding
+		data     string
+		want     bothType
+	}{
+		{encoding: 0, data: "custom:a", want: "custom"},
+		{encoding: EncodingCustom, data: "custom:a", want: "custom"},
+		{encoding: EncodingEnum, data: "a", want: "a"},
+	}
+	for i, test := range tests {
+		r := WithEncodings(New())
+		name := r.Add(&a)
+		if test.encoding != 0 {
+			r.SetEncoding(name, test.encoding)
+		}
+		_, data, err := r.Marshal(&a)
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d Marshal() got %q, want %q", i, data, test.data)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if want := &test.want; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, want)
+		}
+	}
+
+	r := WithEncodings(New())
+	name := r.Add(marshalType{})
+	r.SetEncoding(name, EncodingCustom)
+	if _, err := r.Unmarshal(name, []byte("x"), NoSetup); err == nil {
+		t.Errorf("Unmarshal() with an unsupported encoding wants error, got none")
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		r.SetEncoding(name, EncodingEnum)
+	}()
+	if paniced != "typeregistry typeregistry.marshalType does not support encoding enum" {
+		t.Errorf("Expected SetEncoding() with an unsupported encoding to panic, got %s", paniced)
+	}
+	if got := Encoding(99).String(); got != "Encoding(99)" {
+		t.Errorf("String() got %s, want Encoding(99)", got)
+	}
+}
+
+type ifaceType struct {
+	Name string
+}
+
+func (o ifaceType) MarshalBinary() ([]byte, error) { return []byte("bin:" + o.Name), nil }
+func (o ifaceType) MarshalText() ([]byte, error)   { return []byte("text:" + o.Name), nil }
+func (o ifaceType) MarshalJSON() ([]byte, error)   { return []byte(`"` + o.Name + `"`), nil }
+
+func (o *ifaceType) UnmarshalBinary(data []byte) error {
+	o.Name = "bin:" + string(data)
+	return nil
+}
+
+func (o *ifaceType) UnmarshalText(data []byte) error {
+	o.Name = "text:" + string(data)
+	return nil
+}
+
+func (o *ifaceType) UnmarshalJSON(data []byte) error {
+	o.Name = "json:" + string(data)
+	return nil
+}
+
+func TestEncoding_interfaces(t *testing.T) {
+	tests := []struct {
+		encoding Encoding
+		data     string
+		want     interface{}
+	}{
+		{encoding: 0, data: "bin:a", want: ifaceType{Name: "bin:bin:a"}},
+		{encoding: EncodingBinary, data: "bin:a", want: ifaceType{Name: "bin:bin:a"}},
+		{encoding: EncodingText, data: "text:a", want: ifaceType{Name: "text:text:a"}},
+		{encoding: EncodingJSONMarshaler, data: `"a"`, want: ifaceType{Name: `json:"a"`}},
+	}
+	for i, test := range tests {
+		r := WithEncodings(New())
+		name := r.Add(ifaceType{})
+		if test.encoding != 0 {
+			r.SetEncoding(name, test.encoding)
+		}
+		_, data, err := r.Marshal(ifaceType{Name: "a"})
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d Marshal() got %q, want %q", i, data, test.data)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.want)
+		}
+	}
+
+	r := New()
+	name := r.Add(&ifaceType{})
+	got, err := r.Unmarshal(name, nil, NoSetup)
+	if err != nil {
+		t.Errorf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&ifaceType{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() of no data got %#v, want %#v", got, want)
+	}
+}
+
+func TestEncoding_batch(t *testing.T) {
+	r := WithEncodings(New())
+	name := r.Add(ifaceType{})
+	r.SetEncoding(name, EncodingText)
+	want := ifaceType{Name: "text:text:a"}
+
+	data, err := MarshalBatch(r, []interface{}{ifaceType{Name: "a"}})
+	if err != nil {
+		t.Fatalf("MarshalBatch() wants no error, got: %s", err)
+	}
+	items, err := UnmarshalBatch(r, data, NoSetup)
+	if err != nil {
+		t.Fatalf("UnmarshalBatch() wants no error, got: %s", err)
+	}
+	if got := items[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalBatch() got %#v, want %#v", got, want)
+	}
+
+	data, err = EncodeEnvelope(r, ifaceType{Name: "a"})
+	if err != nil {
+		t.Fatalf("EncodeEnvelope() wants no error, got: %s", err)
+	}
+	got, err := DecodeEnvelope(r, data, NoSetup)
+	if err != nil {
+		t.Fatalf("DecodeEnvelope() wants no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeEnvelope() got %#v, want %#v", got, want)
+	}
+}
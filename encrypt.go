@@ -0,0 +1,95 @@
+package typeregistry
+
+import (
+	"errors"
+	"reflect"
+)
+
+// FieldCipher encrypts and decrypts the fields of structs embedding JSON that
+// have a typeregistry:"encrypt" tag, such as by calling a key management
+// service.
+type FieldCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// errNoFieldCipher is returned when an encrypted field is met by a registry
+// without a FieldCipher.
+var errNoFieldCipher = errors.New("typeregistry has no FieldCipher to encrypt fields")
+
+// CipherRegistry is a Registry that encrypts the typeregistry:"encrypt" fields
+// of structs embedding JSON with its own FieldCipher, so that registries in
+// one process can use different keys. Other registries cannot marshal or
+// unmarshal encrypted fields.
+type CipherRegistry struct {
+	TypeRegistry TypeRegistry
+	Cipher       FieldCipher
+}
+
+// WithFieldCipher wraps a registry so that encrypted fields use c.
+func WithFieldCipher(r TypeRegistry, c FieldCipher) *CipherRegistry {
+	return &CipherRegistry{TypeRegistry: r, Cipher: c}
+}
+
+// Add puts a new type in the registry.
+func (c *CipherRegistry) Add(o interface{}) string {
+	return c.TypeRegistry.Add(o)
+}
+
+// New instantiates a type by name. If the name is unknown, it panics.
+func (c *CipherRegistry) New(name string) interface{} {
+	return c.TypeRegistry.New(name)
+}
+
+// Marshal encodes a type, encrypting its encrypted fields if it embeds JSON.
+func (c *CipherRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return c.marshalWith(c, o)
+}
+
+func (c *CipherRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	if marshalEncoding(o) != EncodingJSON {
+		return c.TypeRegistry.marshalWith(outer, o)
+	}
+	name, err := c.TypeRegistry.marshalName(o)
+	if err != nil {
+		return name, nil, err
+	}
+	if err := normalize(o); err != nil {
+		return name, nil, err
+	}
+	data, err := marshalJSON(o, c.Cipher)
+	return name, data, err
+}
+
+func (c *CipherRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	if marshalEncoding(o) != EncodingJSON {
+		return c.TypeRegistry.appendWith(outer, dst, o)
+	}
+	return appendMarshaled(c, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name, decrypting its encrypted fields if it
+// embeds JSON. If the name is unknown, it panics.
+func (c *CipherRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return c.unmarshalWith(c, name, data, setup)
+}
+
+func (c *CipherRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if len(data) == 0 {
+		data = nil
+	}
+	instance := c.New(name)
+	if setup != nil {
+		setup(instance)
+	}
+	e := unmarshalEncoding(instance)
+	if e != EncodingJSON {
+		return c.TypeRegistry.unmarshalAs(outer, instance, data, e)
+	}
+	defer trace("Unmarshal", reflect.TypeOf(instance))()
+	return unmarshalJSON(instance, data, c.Cipher)
+}
+
+func (c *CipherRegistry) has(name string) bool {
+	return c.TypeRegistry.has(name)
+}
@@ -0,0 +1,81 @@
+package typeregistry
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type xorCipher byte
+
+func (c xorCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	out := make([]byte, len(plaintext))
+	for i, b := range plaintext {
+		out[i] = b ^ byte(c)
+	}
+	return out, nil
+}
+
+func (c xorCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 {
+		return nil, errors.New("empty")
+	}
+	return c.Encrypt(ciphertext)
+}
+
+type secretType struct {
+	JSON
+	Route string
+	Email string            `typeregistry:"encrypt"`
+	Attrs map[string]string `json:"attrs" typeregistry:"encrypt"`
+}
+
+func TestWithFieldCipher(t *testing.T) {
+	plain := New()
+	name := plain.Add(&secretType{})
+	o := &secretType{Route: "eu", Email: "a@example.com", Attrs: map[string]string{"k": "v"}}
+
+	if _, _, err := plain.Marshal(o); err != errNoFieldCipher {
+		t.Errorf("Marshal() without a cipher got %v, want %v", err, errNoFieldCipher)
+	}
+
+	r := WithFieldCipher(plain, xorCipher(0x5a))
+	_, data, err := r.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	want := `{"Route":"eu","Email":"eDsaPyI7Nyo2P3Q5NTd4","attrs":"IXgxeGB4LHgn"}`
+	if string(data) != want {
+		t.Errorf("Marshal() got %s, want %s", data, want)
+	}
+	if bytes.Contains(data, []byte("example")) {
+		t.Errorf("Marshal() leaked plaintext: %s", data)
+	}
+	got, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, o)
+	}
+
+	if _, err := r.Unmarshal(name, []byte(`{"Email":""}`), NoSetup); err == nil {
+		t.Errorf("Unmarshal() wants error from Decrypt, got none")
+	}
+	if _, err := plain.Unmarshal(name, data, NoSetup); err != errNoFieldCipher {
+		t.Errorf("Unmarshal() without a cipher got %v, want %v", err, errNoFieldCipher)
+	}
+
+	other := WithFieldCipher(plain, xorCipher(0x33))
+	_, odata, err := other.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if bytes.Equal(odata, data) {
+		t.Errorf("Marshal() with a different cipher got the same data %s", odata)
+	}
+	if got, err := other.Unmarshal(name, odata, NoSetup); err != nil || !reflect.DeepEqual(got, o) {
+		t.Errorf("Unmarshal() with a different cipher got %#v, %v, want %#v", got, err, o)
+	}
+}
@@ -0,0 +1,62 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Enum is implemented by string types, or pointers to them, that only allow
+// certain values. Marshal encodes an Enum as its string value, and Unmarshal
+// decodes it and returns an error if it is not one of the allowed values.
+type Enum interface {
+	EnumValues() []string
+}
+
+func marshalEnum(e Enum) ([]byte, error) {
+	v, err := enumValue(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEnum(e, v.String()); err != nil {
+		return nil, err
+	}
+	return []byte(v.String()), nil
+}
+
+func unmarshalEnum(e Enum, data []byte) (interface{}, error) {
+	if err := checkEnum(e, string(data)); err != nil {
+		return e, err
+	}
+	if v := reflect.ValueOf(e); v.Kind() == reflect.Ptr {
+		v.Elem().SetString(string(data))
+		return e, nil
+	}
+	v := reflect.New(reflect.TypeOf(e)).Elem()
+	v.SetString(string(data))
+	return v.Interface(), nil
+}
+
+// enumValue returns the string value of e, or an error if e is not a string
+// type or a non-nil pointer to one.
+func enumValue(e Enum) (reflect.Value, error) {
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return v, fmt.Errorf("typeregistry enum %T is not a string type", e)
+	}
+	return v, nil
+}
+
+func checkEnum(e Enum, value string) error {
+	if _, err := enumValue(e); err != nil {
+		return err
+	}
+	for _, allowed := range e.EnumValues() {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("typeregistry %q is not a valid %T", value, e)
+}
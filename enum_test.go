@@ -0,0 +1,65 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type colorType string
+
+func (colorType) EnumValues() []string {
+	return []string{"red", "green"}
+}
+
+type sizeType string
+
+func (*sizeType) EnumValues() []string {
+	return []string{"small", "large"}
+}
+
+type badEnumType int
+
+func (badEnumType) EnumValues() []string {
+	return []string{"1"}
+}
+
+func TestTypeRegistry_Enum(t *testing.T) {
+	small := sizeType("small")
+	tests := []struct {
+		o    interface{}
+		data string
+		err  bool
+	}{
+		{o: colorType("red"), data: "red"},
+		{o: colorType("blue"), err: true},
+		{o: &small, data: "small"},
+		{o: badEnumType(1), err: true},
+	}
+	for i, test := range tests {
+		r := New()
+		name := r.Add(test.o)
+		_, data, err := r.Marshal(test.o)
+		if test.err {
+			if err == nil {
+				t.Errorf("%d Marshal(%#v) wants error, got none", i, test.o)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d Marshal(%#v) wants no error, got: %s", i, test.o, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d Marshal(%#v) got %q, want %q", i, test.o, data, test.data)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.o) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.o)
+		}
+		if _, err := r.Unmarshal(name, []byte("purple"), NoSetup); err == nil {
+			t.Errorf("%d Unmarshal() of an invalid value wants error, got none", i)
+		}
+	}
+}
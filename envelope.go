@@ -0,0 +1,151 @@
+package typeregistry
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// envelopeVersion is the first byte of every encoded Envelope, and
+// envelopeVersioned the first byte of one that carries a module version.
+const (
+	envelopeVersion   = 1
+	envelopeVersioned = 2
+)
+
+// Envelope is a registered name together with the data marshaled for it, so
+// that both can be stored or sent as one value. Its binary form is a version
+// byte of 1, then the name and the data, each prefixed by its length as a
+// uvarint. If the envelope has a Version, the version byte is 2 and the
+// Version follows it, prefixed by its length. Its JSON form is
+// {"name":...,"version":...,"data":...}, with the data in base64 and the
+// version left out if it is empty.
+type Envelope struct {
+	Name string `json:"name"`
+	// Version is the module version of the type that produced the data, as
+	// returned by ModuleVersion, if it was included.
+	Version string `json:"version,omitempty"`
+	Data    []byte `json:"data"`
+}
+
+// MarshalBinary encodes the envelope in its binary form.
+func (e Envelope) MarshalBinary() ([]byte, error) {
+	return e.AppendBinary(nil)
+}
+
+// AppendBinary appends the binary form of the envelope to dst and returns the
+// extended buffer.
+func (e Envelope) AppendBinary(dst []byte) ([]byte, error) {
+	if e.Version != "" {
+		dst = append(dst, envelopeVersioned)
+		dst = appendUvarint(dst, uint64(len(e.Version)))
+		dst = append(dst, e.Version...)
+		return appendFrame(dst, e.Name, e.Data), nil
+	}
+	return appendFrame(append(dst, envelopeVersion), e.Name, e.Data), nil
+}
+
+// UnmarshalBinary decodes the binary form of an envelope. It returns an error
+// if the data is not a complete envelope of a known version.
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errTruncated
+	}
+	var version []byte
+	switch data[0] {
+	case envelopeVersion:
+		data = data[1:]
+	case envelopeVersioned:
+		var err error
+		if version, data, err = readBytes(data[1:]); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("typeregistry envelope has unknown version %d", data[0])
+	}
+	name, payload, rest, err := readFrame(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("typeregistry envelope has trailing data")
+	}
+	e.Name = name
+	e.Version = string(version)
+	e.Data = payload
+	return nil
+}
+
+// EncodeEnvelope marshals o and encodes its name and data as the binary form
+// of an Envelope.
+func (r TypeRegistry) EncodeEnvelope(o interface{}) ([]byte, error) {
+	return EncodeEnvelope(r, o)
+}
+
+// EncodeEnvelope is TypeRegistry.EncodeEnvelope for any Registry.
+func EncodeEnvelope(r Registry, o interface{}) ([]byte, error) {
+	return AppendEnvelope(r, nil, o)
+}
+
+// AppendEnvelope is EncodeEnvelope, but appends the envelope to dst and
+// returns the extended buffer. The data is marshaled with MarshalAppend
+// directly into dst, then moved up to make room for the name, so that a
+// producer can reuse one buffer for many envelopes.
+func (r TypeRegistry) AppendEnvelope(dst []byte, o interface{}) ([]byte, error) {
+	return AppendEnvelope(r, dst, o)
+}
+
+// AppendEnvelope is TypeRegistry.AppendEnvelope for any Registry.
+func AppendEnvelope(r Registry, dst []byte, o interface{}) ([]byte, error) {
+	buf := append(dst, envelopeVersion)
+	start := len(buf)
+	name, buf, err := MarshalAppend(r, buf, o)
+	if err != nil {
+		return dst, err
+	}
+	size := len(buf) - start
+	head := uvarintLen(uint64(len(name))) + len(name) + uvarintLen(uint64(size))
+	buf = append(buf, make([]byte, head)...)
+	copy(buf[start+head:], buf[start:start+size])
+	i := start + binary.PutUvarint(buf[start:], uint64(len(name)))
+	i += copy(buf[i:], name)
+	binary.PutUvarint(buf[i:], uint64(size))
+	return buf, nil
+}
+
+// EncodeVersionedEnvelope is EncodeEnvelope, but includes the module version
+// of o's type, as returned by ModuleVersion, in the envelope.
+func (r TypeRegistry) EncodeVersionedEnvelope(o interface{}) ([]byte, error) {
+	return EncodeVersionedEnvelope(r, o)
+}
+
+// EncodeVersionedEnvelope is TypeRegistry.EncodeVersionedEnvelope for any
+// Registry.
+func EncodeVersionedEnvelope(r Registry, o interface{}) ([]byte, error) {
+	name, data, err := r.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	e := Envelope{Name: name, Data: data}
+	if _, ok := o.(*Unknown); !ok {
+		e.Version = moduleVersionOf(reflect.TypeOf(o))
+	}
+	return e.MarshalBinary()
+}
+
+// DecodeEnvelope decodes the binary form of an Envelope and unmarshals its
+// data as the type it names. The setup function is called as in Unmarshal.
+// If the name is unknown, it returns ErrUnknownType.
+func (r TypeRegistry) DecodeEnvelope(data []byte, setup SetupFunc) (interface{}, error) {
+	return DecodeEnvelope(r, data, setup)
+}
+
+// DecodeEnvelope is TypeRegistry.DecodeEnvelope for any Registry.
+func DecodeEnvelope(r Registry, data []byte, setup SetupFunc) (interface{}, error) {
+	var e Envelope
+	if err := e.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return unmarshalE(r, e.Name, e.Data, setup)
+}
@@ -0,0 +1,57 @@
+package typeregistry
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_EncodeEnvelope(t *testing.T) {
+	r := New()
+	r.Add(&jsonType{})
+
+	data, err := r.EncodeEnvelope(&jsonType{ID: "x"})
+	if err != nil {
+		t.Fatalf("EncodeEnvelope() wants no error, got: %s", err)
+	}
+	want := "\x01\x16*typeregistry.jsonType\x16{\"ID\":\"x\",\"Tags\":null}"
+	if string(data) != want {
+		t.Errorf("EncodeEnvelope() got %q, want %q", data, want)
+	}
+	got, err := r.DecodeEnvelope(data, NoSetup)
+	if err != nil {
+		t.Fatalf("DecodeEnvelope() wants no error, got: %s", err)
+	}
+	if want := (&jsonType{ID: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeEnvelope() got %#v, want %#v", got, want)
+	}
+
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{data: "", err: "typeregistry data is truncated"},
+		{data: "\x03\x00\x00", err: "typeregistry envelope has unknown version 3"},
+		{data: "\x02\x05abc", err: "typeregistry data is truncated"},
+		{data: "\x01\x05abc", err: "typeregistry data is truncated"},
+		{data: "\x01\x00\x00x", err: "typeregistry envelope has trailing data"},
+		{data: "\x01\x04nope\x00", err: `typeregistry does not know "nope"`},
+	}
+	for i, test := range tests {
+		_, err := r.DecodeEnvelope([]byte(test.data), NoSetup)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%d DecodeEnvelope() got error %v, want %s", i, err, test.err)
+		}
+	}
+}
+
+func TestEnvelope_JSON(t *testing.T) {
+	e := Envelope{Name: "a", Data: []byte("hi")}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("json.Marshal() wants no error, got: %s", err)
+	}
+	if want := `{"name":"a","data":"aGk="}`; string(data) != want {
+		t.Errorf("json.Marshal() got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,54 @@
+package typeregistry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNilType is returned by AddE when asked to add nil.
+var ErrNilType = errors.New("typeregistry cannot add nil")
+
+// ErrUnknownType is returned by NewE, UnmarshalE and the functions that decode
+// names from data, such as DecodeEnvelope, when a name is not registered.
+type ErrUnknownType struct {
+	Name string
+}
+
+func (e ErrUnknownType) Error() string {
+	return fmt.Sprintf("typeregistry does not know %#v", e.Name)
+}
+
+// AddE is like Add, but returns an error instead of panicking if the type
+// cannot be registered.
+func (r TypeRegistry) AddE(o interface{}) (string, error) {
+	if err := checkAdd(o); err != nil {
+		return "", err
+	}
+	return r.Add(o), nil
+}
+
+// NewE is like New, but returns ErrUnknownType instead of panicking if the
+// name is unknown, such as when the name was read from untrusted storage.
+func (r TypeRegistry) NewE(name string) (interface{}, error) {
+	if _, ok := r[name]; !ok {
+		return nil, ErrUnknownType{Name: name}
+	}
+	return r.New(name), nil
+}
+
+// UnmarshalE is like Unmarshal, but returns ErrUnknownType instead of
+// panicking if the name is unknown, such as when the name was read from
+// untrusted storage.
+func (r TypeRegistry) UnmarshalE(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return unmarshalE(r, name, data, setup)
+}
+
+// unmarshalE unmarshals data with r, returning ErrUnknownType instead of
+// panicking if r does not know the name. Registries from other packages are
+// asked to unmarshal as they are.
+func unmarshalE(r Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if !knows(r, name) {
+		return nil, ErrUnknownType{Name: name}
+	}
+	return r.Unmarshal(name, data, setup)
+}
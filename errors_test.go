@@ -0,0 +1,98 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_AddE(t *testing.T) {
+	r := New()
+	tests := []struct {
+		o       interface{}
+		want    string
+		wantErr string
+	}{
+		{o: &nameType{}, want: "*typeregistry.nameType"},
+		{o: nil, wantErr: "typeregistry cannot add nil"},
+		{o: make(chan int), wantErr: "typeregistry cannot add chan int of kind chan"},
+	}
+	for i, test := range tests {
+		got, err := r.AddE(test.o)
+		if got != test.want {
+			t.Errorf("%d AddE() got %q, want %q", i, got, test.want)
+		}
+		var errStr string
+		if err != nil {
+			errStr = err.Error()
+		}
+		if errStr != test.wantErr {
+			t.Errorf("%d AddE() got error %q, want %q", i, errStr, test.wantErr)
+		}
+	}
+	if _, err := r.AddE(nil); err != ErrNilType {
+		t.Errorf("AddE(nil) got %v, want ErrNilType", err)
+	}
+}
+
+func TestTypeRegistry_NewE(t *testing.T) {
+	r := New()
+	name := r.Add(&nameType{})
+
+	got, err := r.NewE(name)
+	if err != nil {
+		t.Fatalf("NewE() wants no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(got, &nameType{}) {
+		t.Errorf("NewE() got %#v, want %#v", got, &nameType{})
+	}
+
+	got, err = r.NewE("nope")
+	if got != nil {
+		t.Errorf("NewE() got %#v, want nil", got)
+	}
+	want := ErrUnknownType{Name: "nope"}
+	if err != want {
+		t.Errorf("NewE() got error %#v, want %#v", err, want)
+	}
+	if err.Error() != `typeregistry does not know "nope"` {
+		t.Errorf("NewE() got error %q", err)
+	}
+}
+
+func TestTypeRegistry_UnmarshalE(t *testing.T) {
+	r := New()
+	name := r.Add(&unmarshalType{})
+
+	got, err := r.UnmarshalE(name, []byte("a"), NoSetup)
+	if err != nil {
+		t.Fatalf("UnmarshalE() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:a"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalE() got %#v, want %#v", got, want)
+	}
+
+	got, err = r.UnmarshalE("nope", []byte("a"), NoSetup)
+	if got != nil {
+		t.Errorf("UnmarshalE() got %#v, want nil", got)
+	}
+	if want := (ErrUnknownType{Name: "nope"}); err != want {
+		t.Errorf("UnmarshalE() got error %#v, want %#v", err, want)
+	}
+}
+
+func TestUnmarshalE_wrappers(t *testing.T) {
+	tests := []Registry{
+		WithEncodings(New()),
+		New().Namespace("ns"),
+		MapNames(New(), HashNames([]byte("key"))),
+		Safe(New()),
+		NonNil(New()),
+	}
+	data, _ := Envelope{Name: "nope"}.MarshalBinary()
+	for i, r := range tests {
+		_, err := DecodeEnvelope(r, data, NoSetup)
+		if want := (ErrUnknownType{Name: "nope"}); err != want {
+			t.Errorf("%d DecodeEnvelope() got error %v, want %v", i, err, want)
+		}
+	}
+}
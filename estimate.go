@@ -0,0 +1,35 @@
+package typeregistry
+
+import "reflect"
+
+// Sizer is implemented by types that can report how many bytes Marshal will
+// produce for them without encoding, so that producers can allocate buffers
+// and enforce limits before doing the work.
+type Sizer interface {
+	MarshalSize() int
+}
+
+// EstimateSize returns the number of bytes Marshal is expected to produce for
+// o, without marshaling it. A Sizer reports its own size, an Enum is the
+// length of its value, and a type without an encoding is 0. It returns -1 if
+// the size cannot be known without marshaling.
+func (r TypeRegistry) EstimateSize(o interface{}) int {
+	switch m := o.(type) {
+	case *Unknown:
+		return len(m.Data)
+	case Sizer:
+		return m.MarshalSize()
+	case RegistryMarshaler, Marshaler:
+		return -1
+	case Enum:
+		v, err := enumValue(m)
+		if err != nil {
+			return -1
+		}
+		return v.Len()
+	}
+	if capabilitiesOf(reflect.TypeOf(o)).Has(Marshalable) {
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package typeregistry
+
+import (
+	"testing"
+)
+
+type sizerType struct {
+	marshalType
+}
+
+func (s sizerType) MarshalSize() int {
+	return len("bin:") + len(s.Name)
+}
+
+func TestTypeRegistry_EstimateSize(t *testing.T) {
+	tests := []struct {
+		o    interface{}
+		want int
+	}{
+		{o: sizerType{marshalType{Name: "ok"}}, want: 6},
+		{o: colorType("red"), want: 3},
+		{o: nothingType{}, want: 0},
+		{o: &Unknown{Name: "x", Data: []byte("abc")}, want: 3},
+		{o: marshalType{Name: "ok"}, want: -1},
+		{o: jsonType{}, want: -1},
+	}
+	r := New()
+	for i, test := range tests {
+		if got := r.EstimateSize(test.o); got != test.want {
+			t.Errorf("%d EstimateSize(%#v) got %d, want %d", i, test.o, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package typeregistry_test
+
+import (
+	"fmt"
+
+	"github.com/rcarver/typeregistry"
+)
+
+// Invoice was moved here from package billing.
+type Invoice struct {
+	typeregistry.JSON
+	Number int
+}
+
+func ExampleAliasRegistry_Alias() {
+	registry := typeregistry.WithAliases(typeregistry.New())
+	name := registry.Add(&Invoice{})
+	if err := registry.Alias("*billing.Invoice", name); err != nil {
+		panic(err)
+	}
+
+	// Data stored before the move still unmarshals.
+	o, err := registry.Unmarshal("*billing.Invoice", []byte(`{"Number":7}`), typeregistry.NoSetup)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%T %d\n", o, o.(*Invoice).Number)
+
+	// New data is stored under the current name.
+	name, data, _ := registry.Marshal(o)
+	fmt.Println(name, string(data))
+	// Output:
+	// *typeregistry_test.Invoice 7
+	// *typeregistry_test.Invoice {"Number":7}
+}
@@ -0,0 +1,58 @@
+package typeregistry_test
+
+import (
+	"fmt"
+
+	"github.com/rcarver/typeregistry"
+)
+
+// OrderPlaced is an event encoded as JSON.
+type OrderPlaced struct {
+	typeregistry.JSON
+	OrderID string
+	total   int
+}
+
+// OrderShipped is another event encoded as JSON.
+type OrderShipped struct {
+	typeregistry.JSON
+	OrderID string
+	Carrier string
+}
+
+func ExampleTypeRegistry_MarshalBatch() {
+	registry := typeregistry.New()
+	registry.Add(&OrderPlaced{})
+	registry.Add(&OrderShipped{})
+
+	// Marshal several values of different types into one byte slice, such as
+	// a row in a database.
+	data, err := registry.MarshalBatch([]interface{}{
+		&OrderPlaced{OrderID: "1"},
+		&OrderShipped{OrderID: "1", Carrier: "post"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Unmarshal them again, setting up each value before it's decoded.
+	events, err := registry.UnmarshalBatch(data, func(o interface{}) {
+		if o, ok := o.(*OrderPlaced); ok {
+			o.total = 42
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range events {
+		switch e := e.(type) {
+		case *OrderPlaced:
+			fmt.Printf("placed %s total:%d\n", e.OrderID, e.total)
+		case *OrderShipped:
+			fmt.Printf("shipped %s by %s\n", e.OrderID, e.Carrier)
+		}
+	}
+	// Output:
+	// placed 1 total:42
+	// shipped 1 by post
+}
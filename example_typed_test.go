@@ -0,0 +1,49 @@
+//go:build go1.18
+// +build go1.18
+
+package typeregistry_test
+
+import (
+	"fmt"
+
+	"github.com/rcarver/typeregistry"
+)
+
+// Job is implemented by every type in the jobs registry.
+type Job interface {
+	Run() string
+}
+
+// EmailJob is a Job that sends an email.
+type EmailJob struct {
+	typeregistry.JSON
+	To     string
+	sender string
+}
+
+// Run sends the email.
+func (j *EmailJob) Run() string {
+	return j.sender + " sent email to " + j.To
+}
+
+func ExampleTyped() {
+	jobs := typeregistry.Typed[Job](typeregistry.New())
+	jobs.Add(&EmailJob{})
+
+	name, data, err := jobs.Marshal(&EmailJob{To: "ryan"})
+	if err != nil {
+		panic(err)
+	}
+
+	// The setup function and result are a Job, with no type assertions.
+	job, err := jobs.Unmarshal(name, data, func(j Job) {
+		if j, ok := j.(*EmailJob); ok {
+			j.sender = "mailer"
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(job.Run())
+	// Output: mailer sent email to ryan
+}
@@ -0,0 +1,81 @@
+package typeregistry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation describes how Unmarshal handles some data, for debugging a
+// round trip that misbehaves.
+type Explanation struct {
+	// Name is the registered name that was looked up.
+	Name string
+	// Known reports whether the name is registered. The other fields are
+	// only set if it is.
+	Known bool
+	// Type identifies the type that is instantiated.
+	Type TypeIdentity
+	// Encoding is how the data is decoded, or 0 if the type has no encoding
+	// and the data is ignored.
+	Encoding Encoding
+	// Fields lists the fields whose encoding is changed by a typeregistry
+	// tag, as "Name:codec".
+	Fields []string
+	// Result is the value decoded without setup, in Go syntax.
+	Result string
+	// Error is the error decoding the data without setup, if any.
+	Error string
+}
+
+func (e Explanation) String() string {
+	if !e.Known {
+		return fmt.Sprintf("name: %s\nunknown\n", e.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "name: %s\ntype: %s\n", e.Name, e.Type.ID())
+	if e.Encoding == 0 {
+		b.WriteString("encoding: none\n")
+	} else {
+		fmt.Fprintf(&b, "encoding: %s\n", e.Encoding)
+	}
+	if len(e.Fields) > 0 {
+		fmt.Fprintf(&b, "fields: %s\n", strings.Join(e.Fields, " "))
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&b, "error: %s\n", e.Error)
+	} else {
+		fmt.Fprintf(&b, "result: %s\n", e.Result)
+	}
+	return b.String()
+}
+
+// Explain reports the steps Unmarshal takes to decode data as the type
+// registered as name, and the result of taking them without setup. Unlike
+// Unmarshal, it does not panic if the name is unknown.
+func (r TypeRegistry) Explain(name string, data []byte) Explanation {
+	e := Explanation{Name: name}
+	if _, ok := r[name]; !ok {
+		return e
+	}
+	e.Known = true
+	e.Type = r.Identity(name)
+	instance := r.New(name)
+	e.Encoding = unmarshalEncoding(instance)
+	if v, ok := structOf(instance); ok && e.Encoding == EncodingJSON {
+		fields, err := fieldCodecsOf(v.Type())
+		if err != nil {
+			e.Error = err.Error()
+			return e
+		}
+		for _, f := range fields {
+			e.Fields = append(e.Fields, f.name+":"+f.codec)
+		}
+	}
+	o, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+	e.Result = fmt.Sprintf("%#v", o)
+	return e
+}
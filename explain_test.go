@@ -0,0 +1,72 @@
+package typeregistry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeRegistry_Explain(t *testing.T) {
+	r := New()
+	r.Add(&unmarshalType{})
+	r.Add(&unmarshalFailType{})
+	r.Add(&tagType{})
+	r.Add(nothingType{})
+
+	tests := []struct {
+		name string
+		data string
+		want string
+		// anyError accepts any error message, since it comes from the
+		// standard library.
+		anyError bool
+	}{
+		{
+			name: "*typeregistry.unmarshalType",
+			data: "ok",
+			want: "name: *typeregistry.unmarshalType\n" +
+				"type: github.com/rcarver/typeregistry *typeregistry.unmarshalType\n" +
+				"encoding: custom\n" +
+				"result: &typeregistry.unmarshalType{Name:\"bin:ok\"}\n",
+		},
+		{
+			name: "*typeregistry.unmarshalFailType",
+			want: "name: *typeregistry.unmarshalFailType\n" +
+				"type: github.com/rcarver/typeregistry *typeregistry.unmarshalFailType\n" +
+				"encoding: custom\n" +
+				"error: Failed\n",
+		},
+		{
+			name: "*typeregistry.tagType",
+			data: "{",
+			want: "name: *typeregistry.tagType\n" +
+				"type: github.com/rcarver/typeregistry *typeregistry.tagType\n" +
+				"encoding: json\n" +
+				"fields: At:unix ID:base64 Key:base64 Level:stringer Point:stringer\n" +
+				"error: ",
+			anyError: true,
+		},
+		{
+			name: "typeregistry.nothingType",
+			data: "ignored",
+			want: "name: typeregistry.nothingType\n" +
+				"type: github.com/rcarver/typeregistry typeregistry.nothingType\n" +
+				"encoding: none\n" +
+				"result: typeregistry.nothingType{}\n",
+		},
+		{
+			name: "unknown",
+			want: "name: unknown\nunknown\n",
+		},
+	}
+	for i, test := range tests {
+		got := r.Explain(test.name, []byte(test.data)).String()
+		if test.anyError {
+			if i := strings.LastIndex(got, "\nerror: "); i >= 0 && strings.HasSuffix(got, "\n") {
+				got = got[:i+len("\nerror: ")]
+			}
+		}
+		if got != test.want {
+			t.Errorf("%d Explain() got %q, want %q", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package typeregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Extract returns a single field of JSON encoded data without decoding the
+// rest of it or instantiating its type, for routers that only need a routing
+// key. The path starts with "$" and selects object keys with ".key" and array
+// elements with "[index]", such as "$.User.ID" or "$.Items[0]". The field is
+// returned as encoding/json decodes into an interface{}.
+func Extract(data []byte, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for _, s := range segments {
+		if err := seek(dec, s); err != nil {
+			return nil, fmt.Errorf("typeregistry extract %s: %s", path, err)
+		}
+	}
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("typeregistry extract %s: %s", path, err)
+	}
+	return v, nil
+}
+
+// pathSegment is an object key, or an array index if key is empty.
+type pathSegment struct {
+	key   string
+	index int
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("typeregistry path %q must start with $", path)
+	}
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("typeregistry path %q has an empty key", path)
+			}
+			segments = append(segments, pathSegment{key: rest[1:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("typeregistry path %q has an unclosed [", path)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("typeregistry path %q has a bad index %q", path, rest[1:end])
+			}
+			segments = append(segments, pathSegment{index: i})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("typeregistry path %q is malformed", path)
+		}
+	}
+	return segments, nil
+}
+
+// seek advances dec to the start of the value selected by s.
+func seek(dec *json.Decoder, s pathSegment) error {
+	want := json.Delim('[')
+	if s.key != "" {
+		want = json.Delim('{')
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("want %s, got %v", want, tok)
+	}
+	for i := 0; dec.More(); i++ {
+		if s.key != "" {
+			if tok, err = dec.Token(); err != nil {
+				return err
+			}
+			if tok == s.key {
+				return nil
+			}
+		} else if i == s.index {
+			return nil
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	if s.key != "" {
+		return fmt.Errorf("no key %q", s.key)
+	}
+	return fmt.Errorf("no index %d", s.index)
+}
@@ -0,0 +1,44 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	data := []byte(`{"UserID": "1", "User": {"Name": "Ryan", "Tags": ["a", "b"]}, "Items": [{"ID": 7}, {"ID": 8}]}`)
+	tests := []struct {
+		path string
+		want interface{}
+		err  bool
+	}{
+		{path: "$.UserID", want: "1"},
+		{path: "$.User.Name", want: "Ryan"},
+		{path: "$.User.Tags[1]", want: "b"},
+		{path: "$.Items[1].ID", want: float64(8)},
+		{path: "$.Items[0]", want: map[string]interface{}{"ID": float64(7)}},
+		{path: "$.Missing", err: true},
+		{path: "$.Items[2]", err: true},
+		{path: "$.UserID.Name", err: true},
+		{path: "$.Items.ID", err: true},
+		{path: "UserID", err: true},
+		{path: "$.", err: true},
+		{path: "$.Items[x]", err: true},
+		{path: "$.Items[0", err: true},
+	}
+	for i, test := range tests {
+		got, err := Extract(data, test.path)
+		if test.err {
+			if err == nil {
+				t.Errorf("%d Extract(%s) wants error, got none", i, test.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d Extract(%s) wants no error, got: %s", i, test.path, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Extract(%s) got %#v, want %#v", i, test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package typeregistry
+
+import "encoding/json"
+
+// DecodeFailure records data that failed to unmarshal, so that it can be set
+// aside, such as on a dead-letter queue, and replayed after a fix. It
+// implements Marshaler and Unmarshaler, so add it to a registry to store
+// failures like any other type.
+type DecodeFailure struct {
+	// Name is the registered name the data was unmarshaled as.
+	Name string
+	// Data is the raw data, exactly as it was received.
+	Data []byte
+	// Error is the message of the error that unmarshaling returned.
+	Error string
+	// Meta holds any other information about the failure, such as where the
+	// data came from.
+	Meta map[string]string
+}
+
+// NewDecodeFailure records the failure to unmarshal data by name.
+func NewDecodeFailure(name string, data []byte, err error) *DecodeFailure {
+	f := &DecodeFailure{Name: name, Data: data}
+	if err != nil {
+		f.Error = err.Error()
+	}
+	return f
+}
+
+// Marshal encodes the failure as JSON.
+func (f *DecodeFailure) Marshal() ([]byte, error) {
+	return json.Marshal(f)
+}
+
+// Unmarshal decodes a failure encoded by Marshal.
+func (f *DecodeFailure) Unmarshal(data []byte) error {
+	return json.Unmarshal(data, f)
+}
+
+// Replay unmarshals the failed data again, as Registry.Unmarshal.
+func (f *DecodeFailure) Replay(r Registry, setup SetupFunc) (interface{}, error) {
+	return r.Unmarshal(f.Name, f.Data, setup)
+}
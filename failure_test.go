@@ -0,0 +1,48 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeFailure(t *testing.T) {
+	r := New()
+	name := r.Add(&retryType{})
+	r.Add(&DecodeFailure{})
+
+	data := []byte("ok")
+	_, err := r.Unmarshal(name, data, NoSetup)
+	if err == nil {
+		t.Fatalf("Unmarshal() wants error, got none")
+	}
+	f := NewDecodeFailure(name, data, err)
+	f.Meta = map[string]string{"queue": "orders"}
+
+	fname, fdata, err := r.Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	got, err := r.Unmarshal(fname, fdata, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	want := &DecodeFailure{
+		Name:  name,
+		Data:  data,
+		Error: "service unavailable",
+		Meta:  map[string]string{"queue": "orders"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+
+	o, err := got.(*DecodeFailure).Replay(r, func(o interface{}) {
+		o.(*retryType).available = true
+	})
+	if err != nil {
+		t.Errorf("Replay() wants no error, got: %s", err)
+	}
+	if _, ok := o.(*retryType); !ok {
+		t.Errorf("Replay() got %#v, want *retryType", o)
+	}
+}
@@ -0,0 +1,147 @@
+package typeregistry
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fakeDepth limits how deeply Fake follows pointers, slices, arrays and maps,
+// so that recursive types end.
+const fakeDepth = 3
+
+// Fake instantiates a type by name and fills it with pseudo-random data,
+// which is the same each time for the same seed. It is meant for load and
+// contract tests that need realistic values of every registered type. Only
+// exported fields are filled. Enum types get one of their allowed values, and
+// struct fields can be controlled with a "fake" tag:
+//
+//	Name  string   `fake:"len=12"`         // string, slice or map length
+//	Kind  string   `fake:"enum=a|b|c"`     // one of the values
+//	Cache []byte   `fake:"-"`              // left as the zero value
+//
+// If the name is unknown, it panics.
+func (r TypeRegistry) Fake(name string, seed int64) interface{} {
+	f := faker{rand.New(rand.NewSource(seed))}
+	instance := r.New(name)
+	defer trace("Fake", reflect.TypeOf(instance))()
+	v := reflect.New(reflect.TypeOf(instance)).Elem()
+	v.Set(reflect.ValueOf(instance))
+	if v.Kind() == reflect.Ptr {
+		f.fill(v.Elem(), "", 0)
+	} else {
+		f.fill(v, "", 0)
+	}
+	return v.Interface()
+}
+
+type faker struct {
+	rand *rand.Rand
+}
+
+func (f faker) fill(v reflect.Value, tag string, depth int) {
+	opts := parseFakeTag(tag)
+	if e, ok := v.Addr().Interface().(Enum); ok && v.Kind() == reflect.String {
+		if values := e.EnumValues(); len(values) > 0 {
+			v.SetString(values[f.rand.Intn(len(values))])
+		}
+		return
+	}
+	if len(opts.enum) > 0 && v.Kind() == reflect.String {
+		v.SetString(opts.enum[f.rand.Intn(len(opts.enum))])
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(f.rand.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(f.rand.Int63n(1000))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(f.rand.Int63n(256)))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(f.rand.Intn(100000)) / 100)
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(float64(f.rand.Intn(100)), float64(f.rand.Intn(100))))
+	case reflect.String:
+		b := make([]byte, opts.length(8))
+		for i := range b {
+			b[i] = byte('a' + f.rand.Intn(26))
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		if depth >= fakeDepth {
+			return
+		}
+		n := opts.length(2)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			f.fill(v.Index(i), "", depth+1)
+		}
+	case reflect.Array:
+		if depth >= fakeDepth {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			f.fill(v.Index(i), "", depth+1)
+		}
+	case reflect.Map:
+		if depth >= fakeDepth {
+			return
+		}
+		n := opts.length(2)
+		v.Set(reflect.MakeMap(v.Type()))
+		for i := 0; i < n; i++ {
+			k := reflect.New(v.Type().Key()).Elem()
+			f.fill(k, "", depth+1)
+			e := reflect.New(v.Type().Elem()).Elem()
+			f.fill(e, "", depth+1)
+			v.SetMapIndex(k, e)
+		}
+	case reflect.Ptr:
+		if depth >= fakeDepth {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		f.fill(p.Elem(), tag, depth+1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			tag := field.Tag.Get("fake")
+			if field.PkgPath != "" || tag == "-" {
+				continue
+			}
+			f.fill(v.Field(i), tag, depth)
+		}
+	}
+}
+
+type fakeOptions struct {
+	len  int
+	enum []string
+}
+
+func (o fakeOptions) length(def int) int {
+	if o.len > 0 {
+		return o.len
+	}
+	return def
+}
+
+func parseFakeTag(tag string) fakeOptions {
+	var o fakeOptions
+	for _, opt := range strings.Split(tag, ",") {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "len":
+			o.len, _ = strconv.Atoi(kv[1])
+		case "enum":
+			o.enum = strings.Split(kv[1], "|")
+		}
+	}
+	return o
+}
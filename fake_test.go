@@ -0,0 +1,77 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeType struct {
+	Name   string `fake:"len=12"`
+	Kind   string `fake:"enum=a|b"`
+	Color  colorType
+	Count  int
+	Ratio  float64
+	Tags   []string `fake:"len=3"`
+	Scores map[string]int
+	Next   *fakeType
+	Skip   string `fake:"-"`
+	hidden string
+}
+
+type treeType struct {
+	Kids  []treeType
+	Index map[string]treeType
+	Pairs [2][]treeType
+}
+
+func TestTypeRegistry_Fake(t *testing.T) {
+	r := New()
+	name := r.Add(&fakeType{})
+	a := r.Fake(name, 1).(*fakeType)
+	b := r.Fake(name, 1).(*fakeType)
+	c := r.Fake(name, 2).(*fakeType)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Fake() with the same seed got %#v and %#v", a, b)
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("Fake() with different seeds got the same value %#v", a)
+	}
+
+	if len(a.Name) != 12 {
+		t.Errorf("Fake() Name got %q, want 12 characters", a.Name)
+	}
+	if a.Kind != "a" && a.Kind != "b" {
+		t.Errorf("Fake() Kind got %q, want a or b", a.Kind)
+	}
+	if a.Color != "red" && a.Color != "green" {
+		t.Errorf("Fake() Color got %q, want an allowed value", a.Color)
+	}
+	if len(a.Tags) != 3 || len(a.Scores) == 0 {
+		t.Errorf("Fake() got Tags %#v, Scores %#v, want them filled", a.Tags, a.Scores)
+	}
+	if a.Skip != "" || a.hidden != "" {
+		t.Errorf("Fake() filled skipped fields: %#v", a)
+	}
+	depth := 0
+	for n := a.Next; n != nil; n = n.Next {
+		depth++
+	}
+	if depth != fakeDepth {
+		t.Errorf("Fake() nested %d deep, want %d", depth, fakeDepth)
+	}
+
+	r.Add(treeType{})
+	tree := r.Fake("typeregistry.treeType", 1).(treeType)
+	depth = 0
+	for n := tree; len(n.Kids) > 0; n = n.Kids[0] {
+		depth++
+	}
+	if depth != fakeDepth {
+		t.Errorf("Fake() of a tree nested %d deep, want %d", depth, fakeDepth)
+	}
+
+	r.Add(nameType{})
+	if got := r.Fake("typeregistry.nameType", 1).(nameType); got.Name == "" {
+		t.Errorf("Fake() of a value type got %#v, want it filled", got)
+	}
+}
@@ -0,0 +1,226 @@
+package typeregistry
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fieldCodec is a field of a struct embedding JSON whose encoding is changed
+// by a typeregistry tag.
+type fieldCodec struct {
+	index int
+	name  string
+	key   string
+	codec string
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fieldCodecCache holds the []fieldCodec of each struct type.
+var fieldCodecCache sync.Map
+
+// fieldCodecsOf returns the fields of struct type t that have a typeregistry
+// tag, or an error if a tag is unknown or does not suit its field.
+func fieldCodecsOf(t reflect.Type) ([]fieldCodec, error) {
+	if fields, ok := fieldCodecCache.Load(t); ok {
+		return fields.([]fieldCodec), nil
+	}
+	var fields []fieldCodec
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		codec, ok := f.Tag.Lookup("typeregistry")
+		if !ok || f.PkgPath != "" {
+			continue
+		}
+		key, ok := jsonKey(f)
+		if !ok {
+			continue
+		}
+		var fits bool
+		switch codec {
+		case "unix":
+			fits = f.Type == timeType
+		case "base64":
+			fits = (f.Type.Kind() == reflect.Slice || f.Type.Kind() == reflect.Array) && f.Type.Elem().Kind() == reflect.Uint8
+		case "stringer":
+			fits = f.Type.Implements(stringerType) || reflect.PtrTo(f.Type).Implements(stringerType)
+		case "encrypt":
+			fits = true
+		default:
+			return nil, fmt.Errorf("typeregistry field %s.%s has unknown encoding %#v", t, f.Name, codec)
+		}
+		if !fits {
+			return nil, fmt.Errorf("typeregistry field %s.%s of type %s cannot use encoding %#v", t, f.Name, f.Type, codec)
+		}
+		fields = append(fields, fieldCodec{index: i, name: f.Name, key: key, codec: codec})
+	}
+	fieldCodecCache.Store(t, fields)
+	return fields, nil
+}
+
+// jsonKey returns the key encoding/json uses for field f, or false if it
+// leaves the field out.
+func jsonKey(f reflect.StructField) (string, bool) {
+	tag := strings.Split(f.Tag.Get("json"), ",")[0]
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+	return tag, true
+}
+
+// marshal returns the JSON encoding of field value v.
+func (f fieldCodec) marshal(v reflect.Value, c FieldCipher) (json.RawMessage, error) {
+	switch f.codec {
+	case "unix":
+		return json.Marshal(v.Interface().(time.Time).Unix())
+	case "base64":
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return json.RawMessage("null"), nil
+		}
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return json.Marshal(base64.StdEncoding.EncodeToString(b))
+	case "encrypt":
+		if c == nil {
+			return nil, errNoFieldCipher
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if data, err = c.Encrypt(data); err != nil {
+			return nil, err
+		}
+		return json.Marshal(data)
+	}
+	if !v.Type().Implements(stringerType) {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p
+	}
+	return json.Marshal(v.Interface().(fmt.Stringer).String())
+}
+
+// unmarshal converts the JSON encoding of a field of type t back to the form
+// encoding/json expects. It returns false if the field cannot be decoded.
+func (f fieldCodec) unmarshal(raw json.RawMessage, t reflect.Type, c FieldCipher) (json.RawMessage, bool, error) {
+	if bytes.Equal(raw, []byte("null")) {
+		return raw, true, nil
+	}
+	switch f.codec {
+	case "unix":
+		var n int64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, false, err
+		}
+		data, err := json.Marshal(time.Unix(n, 0).UTC())
+		return data, true, err
+	case "base64":
+		if t.Kind() == reflect.Slice {
+			return raw, true, nil
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, false, err
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(b) != t.Len() {
+			return nil, false, fmt.Errorf("typeregistry field %s has %d bytes, want %d", f.name, len(b), t.Len())
+		}
+		a := reflect.New(t).Elem()
+		reflect.Copy(a, reflect.ValueOf(b))
+		data, err := json.Marshal(a.Interface())
+		return data, true, err
+	case "encrypt":
+		if c == nil {
+			return nil, false, errNoFieldCipher
+		}
+		var b []byte
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, false, err
+		}
+		data, err := c.Decrypt(b)
+		return data, true, err
+	}
+	return raw, reflect.PtrTo(t).Implements(textUnmarshalerType), nil
+}
+
+// jsonMember is one member of a JSON object.
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// readObject returns the members of a JSON object, in order.
+func readObject(data []byte) ([]jsonMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("typeregistry cannot read %s as an object", data)
+	}
+	var members []jsonMember
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var m jsonMember
+		m.key = tok.(string)
+		if err := dec.Decode(&m.value); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// writeObject encodes members as a JSON object.
+func writeObject(members []jsonMember) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// structOf returns the struct that o is or points to, or false if it is not
+// one.
+func structOf(o interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(o)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct
+}
@@ -0,0 +1,101 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type levelType int
+
+func (l levelType) String() string {
+	return [...]string{"low", "high"}[l]
+}
+
+func (l *levelType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return fmt.Errorf("bad level %s", text)
+	}
+	return nil
+}
+
+type pointType struct{ X, Y int }
+
+func (p pointType) String() string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+type tagType struct {
+	JSON
+	At      time.Time `json:"at" typeregistry:"unix"`
+	ID      [4]byte   `typeregistry:"base64"`
+	Key     []byte    `typeregistry:"base64"`
+	Level   levelType `json:"level" typeregistry:"stringer"`
+	Point   pointType `typeregistry:"stringer"`
+	Skipped []byte    `json:"-" typeregistry:"base64"`
+}
+
+type badTagType struct {
+	JSON
+	Name string `typeregistry:"unix"`
+}
+
+type unknownTagType struct {
+	JSON
+	Name string `typeregistry:"rot13"`
+}
+
+func TestJSON_tags(t *testing.T) {
+	r := New()
+	name := r.Add(&tagType{})
+	o := &tagType{
+		At:      time.Unix(1500000000, 0).UTC(),
+		ID:      [4]byte{1, 2, 3, 4},
+		Key:     []byte("key"),
+		Level:   1,
+		Point:   pointType{X: 1, Y: 2},
+		Skipped: []byte("x"),
+	}
+	_, data, err := r.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	want := `{"at":1500000000,"ID":"AQIDBA==","Key":"a2V5","level":"high","Point":"1,2"}`
+	if string(data) != want {
+		t.Errorf("Marshal() got %s, want %s", data, want)
+	}
+	got, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	o.Point = pointType{}
+	o.Skipped = nil
+	if !reflect.DeepEqual(got, o) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, o)
+	}
+
+	tests := []string{
+		`{"at":"now"}`,
+		`{"ID":"AQID"}`,
+		`{"ID":"!"}`,
+		`{"level":"medium"}`,
+		`[]`,
+	}
+	for i, data := range tests {
+		if _, err := r.Unmarshal(name, []byte(data), NoSetup); err == nil {
+			t.Errorf("%d Unmarshal(%s) wants error, got none", i, data)
+		}
+	}
+
+	for i, o := range []interface{}{badTagType{}, unknownTagType{}} {
+		if _, _, err := r.Marshal(o); err == nil {
+			t.Errorf("%d Marshal(%#v) wants error, got none", i, o)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package typeregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"text/tabwriter"
+)
+
+// Style selects how Format renders a value.
+type Style int
+
+const (
+	// StyleGo renders a value in Go syntax, as the %#v verb.
+	StyleGo Style = iota
+	// StyleJSON renders a value as indented JSON. Values that cannot be
+	// encoded as JSON are rendered in Go syntax.
+	StyleJSON
+	// StyleTable renders the registered name of a value followed by one line
+	// per exported field.
+	StyleTable
+)
+
+// Format renders a value in a human readable style, for logs and tools.
+func (r TypeRegistry) Format(o interface{}, style Style) string {
+	switch style {
+	case StyleJSON:
+		data, err := json.MarshalIndent(o, "", "  ")
+		if err == nil {
+			return string(data)
+		}
+	case StyleTable:
+		return r.formatTable(o)
+	}
+	return fmt.Sprintf("%#v", o)
+}
+
+// FormatData unmarshals data as the type registered as name, without setup,
+// and renders it with Format. If the name is unknown, it panics.
+func (r TypeRegistry) FormatData(name string, data []byte, style Style) (string, error) {
+	o, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		return "", err
+	}
+	return r.Format(o, style), nil
+}
+
+func (r TypeRegistry) formatTable(o interface{}) string {
+	var buf bytes.Buffer
+	buf.WriteString(r.name(o))
+	buf.WriteString("\n")
+	v := reflect.ValueOf(o)
+	defer trace("Format", v.Type())()
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		fmt.Fprintf(&buf, "%v\n", v.Interface())
+		return buf.String()
+	}
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%v\n", f.Name, v.Field(i).Interface())
+	}
+	w.Flush()
+	return buf.String()
+}
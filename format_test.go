@@ -0,0 +1,54 @@
+package typeregistry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeRegistry_Format(t *testing.T) {
+	tests := []struct {
+		o     interface{}
+		style Style
+		want  string
+	}{
+		{
+			o:     &nameType{Name: "ok"},
+			style: StyleGo,
+			want:  `&typeregistry.nameType{Name:"ok"}`,
+		},
+		{
+			o:     &nameType{Name: "ok"},
+			style: StyleJSON,
+			want:  "{\n  \"Name\": \"ok\"\n}",
+		},
+		{
+			o:     marshalType{Name: "ok"},
+			style: StyleTable,
+			want:  "typeregistry.marshalType\nName  ok\nFail  false\n",
+		},
+		{
+			o:     "text",
+			style: StyleTable,
+			want:  "string\ntext\n",
+		},
+	}
+	r := New()
+	for i, test := range tests {
+		got := r.Format(test.o, test.style)
+		if got != test.want {
+			t.Errorf("%d Format() got %q, want %q", i, got, test.want)
+		}
+	}
+	if got := r.Format(make(chan int), StyleJSON); !strings.HasPrefix(got, "(chan int)") {
+		t.Errorf("Format() of a chan as JSON got %q, want Go syntax", got)
+	}
+
+	name := r.Add(&unmarshalType{})
+	got, err := r.FormatData(name, []byte("ok"), StyleTable)
+	if err != nil {
+		t.Fatalf("FormatData() wants no error, got: %s", err)
+	}
+	if want := "*typeregistry.unmarshalType\nName  bin:ok\n"; got != want {
+		t.Errorf("FormatData() got %q, want %q", got, want)
+	}
+}
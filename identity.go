@@ -0,0 +1,88 @@
+package typeregistry
+
+import (
+	"reflect"
+	"strings"
+)
+
+// TypeIdentity describes which type a registration refers to, in one place,
+// so that features which need to tell types apart agree on how to do it.
+type TypeIdentity struct {
+	// Name is the registered name.
+	Name string
+	// Type is the Go type, such as "*orders.Placed[int]".
+	Type string
+	// PkgPath is the import path of the package that defines the type.
+	PkgPath string
+	// Module and Version identify the module that provides the package, as
+	// recorded in the running binary's build information, if known.
+	Module  string
+	Version string
+	// TypeArgs are the type arguments of an instantiated generic type.
+	TypeArgs []string
+}
+
+// ID identifies the type across processes by its package path and name. It
+// is the form used by SaveConfig and Registrar.Dump.
+func (id TypeIdentity) ID() string {
+	if id.PkgPath != "" {
+		return id.PkgPath + " " + id.Type
+	}
+	return id.Type
+}
+
+// Identity returns the TypeIdentity of the type registered as name. If the
+// name is unknown, it panics.
+func (r TypeRegistry) Identity(name string) TypeIdentity {
+	t, ok := r[name]
+	if !ok {
+		r.New(name)
+	}
+	id := identityOf(t)
+	id.Name = name
+	id.Module, id.Version = moduleOf(id.PkgPath)
+	return id
+}
+
+// identityOf returns the parts of a TypeIdentity that come from t alone.
+func identityOf(t reflect.Type) TypeIdentity {
+	id := TypeIdentity{Type: t.String(), PkgPath: pkgPath(t)}
+	for t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+			t = t.Elem()
+			continue
+		}
+		return id
+	}
+	id.TypeArgs = typeArgs(t.Name())
+	return id
+}
+
+// typeArgs returns the type arguments in a type name such as
+// "Pair[int,map[string]int]".
+func typeArgs(name string) []string {
+	open := strings.IndexByte(name, '[')
+	if open < 0 || !strings.HasSuffix(name, "]") {
+		return nil
+	}
+	var (
+		args  []string
+		depth int
+		start = open + 1
+	)
+	for i := start; i < len(name)-1; i++ {
+		switch name[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, name[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, name[start:len(name)-1])
+}
@@ -0,0 +1,64 @@
+package typeregistry
+
+import (
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+func TestTypeRegistry_Identity(t *testing.T) {
+	defer func(f func() (*debug.BuildInfo, bool)) { readBuildInfo = f }(readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Deps: []*debug.Module{{Path: "github.com/rcarver/typeregistry", Version: "v1.2.3"}},
+		}, true
+	}
+
+	r := New()
+	r.Add(&nothingType{})
+	r.Add(0)
+	tests := []struct {
+		name string
+		want TypeIdentity
+	}{
+		{
+			name: "*typeregistry.nothingType",
+			want: TypeIdentity{
+				Name:    "*typeregistry.nothingType",
+				Type:    "*typeregistry.nothingType",
+				PkgPath: "github.com/rcarver/typeregistry",
+				Module:  "github.com/rcarver/typeregistry",
+				Version: "v1.2.3",
+			},
+		},
+		{
+			name: "int",
+			want: TypeIdentity{Name: "int", Type: "int"},
+		},
+	}
+	for i, test := range tests {
+		if got := r.Identity(test.name); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Identity(%s) got %#v, want %#v", i, test.name, got, test.want)
+		}
+	}
+	if got, want := r.Identity("*typeregistry.nothingType").ID(), "github.com/rcarver/typeregistry *typeregistry.nothingType"; got != want {
+		t.Errorf("ID() got %s, want %s", got, want)
+	}
+}
+
+func TestTypeArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "Order", want: nil},
+		{name: "Box[int]", want: []string{"int"}},
+		{name: "Pair[string,map[string]int]", want: []string{"string", "map[string]int"}},
+		{name: "Nested[pkg.Box[int],[]uint8]", want: []string{"pkg.Box[int]", "[]uint8"}},
+	}
+	for i, test := range tests {
+		if got := typeArgs(test.name); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d typeArgs(%s) got %#v, want %#v", i, test.name, got, test.want)
+		}
+	}
+}
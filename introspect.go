@@ -0,0 +1,44 @@
+package typeregistry
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Names returns every registered name, in sorted order.
+func (r TypeRegistry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether name is registered.
+func (r TypeRegistry) Has(name string) bool {
+	_, ok := r[name]
+	return ok
+}
+
+// hasType reports whether t is registered under any name, including names
+// given by AddNamed and AddNames.
+func (r TypeRegistry) hasType(t reflect.Type) bool {
+	for _, other := range r {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove takes the type registered as name out of the registry. Removing an
+// unknown name does nothing.
+func (r TypeRegistry) Remove(name string) {
+	delete(r, name)
+}
+
+// Len returns the number of registered names.
+func (r TypeRegistry) Len() int {
+	return len(r)
+}
@@ -0,0 +1,43 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_Names(t *testing.T) {
+	r := New()
+	if got := r.Names(); len(got) != 0 || r.Len() != 0 {
+		t.Errorf("Names() of an empty registry got %v", got)
+	}
+	r.Add(&nameType{})
+	r.Add(&jsonType{})
+	r.Add(nothingType{})
+
+	want := []string{"*typeregistry.jsonType", "*typeregistry.nameType", "typeregistry.nothingType"}
+	if got := r.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() got %v, want %v", got, want)
+	}
+	if got := r.Len(); got != 3 {
+		t.Errorf("Len() got %d, want 3", got)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "*typeregistry.nameType", want: true},
+		{name: "typeregistry.nameType", want: false},
+	}
+	for i, test := range tests {
+		if got := r.Has(test.name); got != test.want {
+			t.Errorf("%d Has(%q) got %v, want %v", i, test.name, got, test.want)
+		}
+	}
+
+	r.Remove("*typeregistry.nameType")
+	r.Remove("nope")
+	if r.Has("*typeregistry.nameType") || r.Len() != 2 {
+		t.Errorf("Remove() left %v", r.Names())
+	}
+}
@@ -0,0 +1,58 @@
+//go:build js && wasm
+// +build js,wasm
+
+package typeregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+)
+
+// JSTypeKey is the key of the JavaScript object property that holds the
+// registered name of a value exchanged with MarshalJS and UnmarshalJS.
+const JSTypeKey = "type"
+
+// MarshalJS converts o to a JavaScript object holding its fields, as encoded
+// by encoding/json, and its registered name under JSTypeKey. The value must
+// encode to a JSON object.
+func (r TypeRegistry) MarshalJS(o interface{}) (js.Value, error) {
+	name, err := r.marshalName(o)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	v := js.Global().Get("JSON").Call("parse", string(data))
+	if v.Type() != js.TypeObject || v.IsNull() {
+		return js.Undefined(), fmt.Errorf("typeregistry %s is not a JavaScript object", name)
+	}
+	v.Set(JSTypeKey, name)
+	return v, nil
+}
+
+// UnmarshalJS instantiates the type named by the JSTypeKey property of a
+// JavaScript object and fills it from the object's other properties using
+// encoding/json. The setup function is called before the fields are set, as
+// in Unmarshal.
+func (r TypeRegistry) UnmarshalJS(v js.Value, setup SetupFunc) (interface{}, error) {
+	if v.Type() != js.TypeObject || v.IsNull() {
+		return nil, fmt.Errorf("typeregistry cannot unmarshal JavaScript %s", v.Type())
+	}
+	name := v.Get(JSTypeKey)
+	if name.Type() != js.TypeString {
+		return nil, fmt.Errorf("typeregistry JavaScript object has no %#v", JSTypeKey)
+	}
+	object := js.Global().Get("Object")
+	fields := object.Call("assign", object.New(), v)
+	fields.Delete(JSTypeKey)
+	data := js.Global().Get("JSON").Call("stringify", fields).String()
+
+	instance := r.New(name.String())
+	if setup != nil {
+		setup(instance)
+	}
+	return unmarshalJSON(instance, []byte(data), nil)
+}
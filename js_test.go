@@ -0,0 +1,46 @@
+//go:build js && wasm
+// +build js,wasm
+
+package typeregistry
+
+import (
+	"reflect"
+	"syscall/js"
+	"testing"
+)
+
+func TestTypeRegistry_MarshalJS(t *testing.T) {
+	tests := []struct {
+		o interface{}
+	}{
+		{o: nameType{Name: "value"}},
+		{o: &nameType{Name: "pointer"}},
+	}
+	for i, test := range tests {
+		r := New()
+		name := r.Add(test.o)
+		v, err := r.MarshalJS(test.o)
+		if err != nil {
+			t.Fatalf("%d MarshalJS() wants no error, got: %s", i, err)
+		}
+		if got := v.Get(JSTypeKey).String(); got != name {
+			t.Errorf("%d MarshalJS() type got %s, want %s", i, got, name)
+		}
+		got, err := r.UnmarshalJS(v, NoSetup)
+		if err != nil {
+			t.Errorf("%d UnmarshalJS() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.o) {
+			t.Errorf("%d UnmarshalJS() got %#v, want %#v", i, got, test.o)
+		}
+	}
+
+	r := New()
+	r.Add(nameType{})
+	if _, err := r.MarshalJS("string"); err == nil {
+		t.Errorf("MarshalJS() of a string wants error, got none")
+	}
+	if _, err := r.UnmarshalJS(js.ValueOf(map[string]interface{}{"Name": "x"}), NoSetup); err == nil {
+		t.Errorf("UnmarshalJS() without a type wants error, got none")
+	}
+}
@@ -0,0 +1,122 @@
+package typeregistry
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// JSON can be embedded in a struct to have the registry encode the whole
+// struct with encoding/json, as if it implemented Marshaler and Unmarshaler
+// that way. Embedding it saves writing the two methods for each type:
+//
+//	type OrderPlaced struct {
+//		typeregistry.JSON
+//		OrderID string
+//	}
+//
+// A typeregistry tag on a field changes how that field is encoded:
+//
+//	typeregistry:"unix"      a time.Time as seconds since the Unix epoch, decoded in UTC
+//	typeregistry:"base64"    a byte slice or array as a base64 string
+//	typeregistry:"stringer"  a fmt.Stringer as the result of String, decoded
+//	                         only if its pointer implements encoding.TextUnmarshaler
+//	typeregistry:"encrypt"   any value, encrypted by the FieldCipher of a
+//	                         CipherRegistry and stored as a base64 string
+//
+// Tags apply to the struct's own fields, not to those of embedded structs.
+type JSON struct{}
+
+func (JSON) typeregistryJSON() {}
+
+type jsonSelf interface {
+	typeregistryJSON()
+}
+
+// marshalJSON encodes o with encoding/json, then re-encodes its fields that
+// have a typeregistry tag.
+func marshalJSON(o interface{}, c FieldCipher) ([]byte, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := structOf(o)
+	if !ok {
+		return data, nil
+	}
+	fields, err := fieldCodecsOf(v.Type())
+	if err != nil || len(fields) == 0 {
+		return data, err
+	}
+	members, err := readObject(data)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range members {
+		for _, f := range fields {
+			if f.key != m.key {
+				continue
+			}
+			if members[i].value, err = f.marshal(v.Field(f.index), c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return writeObject(members), nil
+}
+
+// unmarshalJSON decodes data into instance, which may be a pointer or a
+// value, returning the result. Fields that have a typeregistry tag are
+// converted back before decoding. Empty data leaves instance as it is.
+func unmarshalJSON(instance interface{}, data []byte, c FieldCipher) (interface{}, error) {
+	if len(data) == 0 {
+		return instance, nil
+	}
+	data, err := restoreFields(instance, data, c)
+	if err != nil {
+		return instance, err
+	}
+	if reflect.TypeOf(instance).Kind() == reflect.Ptr {
+		err := json.Unmarshal(data, instance)
+		return instance, err
+	}
+	ptr := reflect.New(reflect.TypeOf(instance))
+	ptr.Elem().Set(reflect.ValueOf(instance))
+	err = json.Unmarshal(data, ptr.Interface())
+	return ptr.Elem().Interface(), err
+}
+
+// restoreFields converts the members of data for fields of instance that have
+// a typeregistry tag back to the form encoding/json expects.
+func restoreFields(instance interface{}, data []byte, c FieldCipher) ([]byte, error) {
+	t := reflect.TypeOf(instance)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return data, nil
+	}
+	fields, err := fieldCodecsOf(t)
+	if err != nil || len(fields) == 0 {
+		return data, err
+	}
+	members, err := readObject(data)
+	if err != nil {
+		return nil, err
+	}
+	kept := members[:0]
+	for _, m := range members {
+		keep := true
+		for _, f := range fields {
+			if f.key != m.key {
+				continue
+			}
+			if m.value, keep, err = f.unmarshal(m.value, t.Field(f.index).Type, c); err != nil {
+				return nil, err
+			}
+		}
+		if keep {
+			kept = append(kept, m)
+		}
+	}
+	return writeObject(kept), nil
+}
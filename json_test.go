@@ -0,0 +1,58 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type jsonType struct {
+	JSON
+	ID   string
+	Tags []string
+	svc  *nameType
+}
+
+func TestJSON(t *testing.T) {
+	svc := &nameType{}
+	tests := []struct {
+		o    interface{}
+		data string
+		want interface{}
+	}{
+		{
+			o:    &jsonType{ID: "1", Tags: []string{"a"}},
+			data: `{"ID":"1","Tags":["a"]}`,
+			want: &jsonType{ID: "1", Tags: []string{"a"}, svc: svc},
+		},
+		{
+			o:    jsonType{ID: "2"},
+			data: `{"ID":"2","Tags":null}`,
+			want: jsonType{ID: "2"},
+		},
+	}
+	for i, test := range tests {
+		r := New()
+		name := r.Add(test.o)
+		_, data, err := r.Marshal(test.o)
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d Marshal() got %s, want %s", i, data, test.data)
+		}
+		got, err := r.Unmarshal(name, data, func(o interface{}) {
+			if j, ok := o.(*jsonType); ok {
+				j.svc = svc
+			}
+		})
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.want)
+		}
+		if _, err := r.Unmarshal(name, []byte("{"), NoSetup); err == nil {
+			t.Errorf("%d Unmarshal() of bad data wants error, got none", i)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+//go:build goexperiment.jsonv2
+// +build goexperiment.jsonv2
+
+package typeregistry
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"reflect"
+)
+
+// JSONv2Codec is a Codec using encoding/json/v2, which encodes maps in a
+// deterministic order. It is only available when building with
+// GOEXPERIMENT=jsonv2.
+var JSONv2Codec Codec = jsonv2Codec{}
+
+type jsonv2Codec struct{}
+
+func (jsonv2Codec) Name() string { return "jsonv2" }
+
+func (jsonv2Codec) Marshal(o interface{}) ([]byte, error) {
+	return jsonv2.Marshal(o, jsonv2.Deterministic(true))
+}
+
+func (jsonv2Codec) Unmarshal(data []byte, o interface{}) error {
+	return jsonv2.Unmarshal(data, o)
+}
+
+// discriminated is the JSON form of a value together with its registered
+// name.
+type discriminated struct {
+	Type  string         `json:"type"`
+	Value jsontext.Value `json:"value"`
+}
+
+// MarshalJSONv2 encodes o with JSONv2Codec, together with its registered name
+// as a discriminator, so that UnmarshalJSONv2 can decode it without being told
+// its type. It is only available when building with GOEXPERIMENT=jsonv2.
+func (r TypeRegistry) MarshalJSONv2(o interface{}) ([]byte, error) {
+	name, err := r.marshalName(o)
+	if err != nil {
+		return nil, err
+	}
+	value, err := JSONv2Codec.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return jsonv2.Marshal(discriminated{Type: name, Value: value})
+}
+
+// UnmarshalJSONv2 decodes data encoded by MarshalJSONv2, instantiating the
+// type named by its discriminator. The setup function is called before the
+// value is decoded, as in Unmarshal. If the name is unknown, it panics.
+func (r TypeRegistry) UnmarshalJSONv2(data []byte, setup SetupFunc) (interface{}, error) {
+	var d discriminated
+	if err := jsonv2.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	instance := r.New(d.Type)
+	if setup != nil {
+		setup(instance)
+	}
+	if reflect.TypeOf(instance).Kind() == reflect.Ptr {
+		return instance, JSONv2Codec.Unmarshal(d.Value, instance)
+	}
+	ptr := reflect.New(reflect.TypeOf(instance))
+	ptr.Elem().Set(reflect.ValueOf(instance))
+	err := JSONv2Codec.Unmarshal(d.Value, ptr.Interface())
+	return ptr.Elem().Interface(), err
+}
@@ -0,0 +1,48 @@
+//go:build goexperiment.jsonv2
+// +build goexperiment.jsonv2
+
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_MarshalJSONv2(t *testing.T) {
+	tests := []struct {
+		o    interface{}
+		data string
+	}{
+		{
+			o:    &nameType{Name: "ok"},
+			data: `{"type":"*typeregistry.nameType","value":{"Name":"ok"}}`,
+		},
+		{
+			o:    patchType{Limits: map[string]int{"b": 2, "a": 1}},
+			data: `{"type":"typeregistry.patchType","value":{"Name":"","Tags":[],"Limits":{"a":1,"b":2}}}`,
+		},
+	}
+	for i, test := range tests {
+		r := New()
+		r.Add(test.o)
+		data, err := r.MarshalJSONv2(test.o)
+		if err != nil {
+			t.Errorf("%d MarshalJSONv2() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d MarshalJSONv2() got %s, want %s", i, data, test.data)
+		}
+		got, err := r.UnmarshalJSONv2(data, NoSetup)
+		if err != nil {
+			t.Errorf("%d UnmarshalJSONv2() wants no error, got: %s", i, err)
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(test.o) {
+			t.Errorf("%d UnmarshalJSONv2() got %#v, want %#v", i, got, test.o)
+		}
+	}
+
+	r := New()
+	if _, err := r.UnmarshalJSONv2([]byte("{"), NoSetup); err == nil {
+		t.Errorf("UnmarshalJSONv2() of bad data wants error, got none")
+	}
+}
@@ -0,0 +1,134 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// LazyRegistry is a Registry that can hold types whose prototype is not
+// created until the type is first used. This defers expensive setup, and
+// breaks init order cycles between packages that add to a shared registry.
+// Like SafeRegistry, it copies the registry when a type is added or created,
+// so it can be used from many goroutines at once.
+type LazyRegistry struct {
+	v atomic.Value
+
+	mu      sync.Mutex
+	pending map[string]*lazyEntry
+}
+
+// lazyEntry is a type added lazily. Its mutex is held while its factory runs,
+// so that the factory runs once at a time without locking the registry.
+type lazyEntry struct {
+	mu      sync.Mutex
+	factory func() interface{}
+}
+
+// Lazy returns a LazyRegistry holding a copy of r, so that types can be added
+// lazily.
+func Lazy(r TypeRegistry) *LazyRegistry {
+	l := &LazyRegistry{pending: make(map[string]*lazyEntry)}
+	l.v.Store(r.clone())
+	return l
+}
+
+// Load returns the current registry, without the types that are still
+// pending. It must not be modified.
+func (l *LazyRegistry) Load() TypeRegistry {
+	return l.v.Load().(TypeRegistry)
+}
+
+// AddLazy registers name, calling factory to create the prototype the first
+// time the name is used by New or Unmarshal. Since Marshal names values by
+// their type, name must be the name that Add would return for the prototype,
+// otherwise the first use panics. The factory may use the registry, such as
+// to create other lazy types. If it panics, the name stays pending and the
+// next use calls it again.
+func (l *LazyRegistry) AddLazy(name string, factory func() interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending[name] = &lazyEntry{factory: factory}
+}
+
+// Add puts a new type in the registry. If the type cannot be registered, it
+// panics.
+func (l *LazyRegistry) Add(o interface{}) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.Load().clone()
+	name := r.Add(o)
+	l.v.Store(r)
+	return name
+}
+
+// New instantiates a type by name, creating its prototype first if it was
+// added lazily. If the name is unknown, it panics.
+func (l *LazyRegistry) New(name string) interface{} {
+	l.resolve(name)
+	return l.Load().New(name)
+}
+
+// Marshal encodes a type.
+func (l *LazyRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return l.marshalWith(l, o)
+}
+
+func (l *LazyRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return l.Load().marshalWith(outer, o)
+}
+
+func (l *LazyRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return l.Load().appendWith(outer, dst, o)
+}
+
+// Unmarshal decodes a type by name, creating its prototype first if it was
+// added lazily. If the name is unknown, it panics.
+func (l *LazyRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return l.unmarshalWith(l, name, data, setup)
+}
+
+func (l *LazyRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	l.resolve(name)
+	return l.Load().unmarshalWith(outer, name, data, setup)
+}
+
+func (l *LazyRegistry) has(name string) bool {
+	l.mu.Lock()
+	_, ok := l.pending[name]
+	l.mu.Unlock()
+	return ok || l.Load().has(name)
+}
+
+func (l *LazyRegistry) resolve(name string) {
+	l.mu.Lock()
+	e, ok := l.pending[name]
+	l.mu.Unlock()
+	if !ok {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	l.mu.Lock()
+	ok = l.pending[name] == e
+	l.mu.Unlock()
+	if !ok {
+		return
+	}
+	o := e.factory()
+	if o == nil {
+		panic(fmt.Sprintf("typeregistry lazy %#v created nil", name))
+	}
+	if got := l.Load().name(o); got != name {
+		panic(fmt.Sprintf("typeregistry lazy %#v created %#v", name, got))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending[name] == e {
+		delete(l.pending, name)
+	}
+	r := l.Load().clone()
+	r[name] = reflect.TypeOf(o)
+	l.v.Store(r)
+}
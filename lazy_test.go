@@ -0,0 +1,119 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	l := Lazy(New())
+	l.AddLazy("*typeregistry.unmarshalType", func() interface{} {
+		calls++
+		return &unmarshalType{}
+	})
+	if calls != 0 {
+		t.Errorf("AddLazy() called the factory %d times, want 0", calls)
+	}
+	if got := l.New("*typeregistry.unmarshalType"); !reflect.DeepEqual(got, &unmarshalType{}) {
+		t.Errorf("New() got %#v, want %#v", got, &unmarshalType{})
+	}
+	got, err := l.Unmarshal("*typeregistry.unmarshalType", []byte("ok"), NoSetup)
+	if err != nil {
+		t.Errorf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:ok"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	if calls != 1 {
+		t.Errorf("factory called %d times, want 1", calls)
+	}
+
+	bad := []struct {
+		factory func() interface{}
+		want    string
+	}{
+		{
+			factory: func() interface{} { return nil },
+			want:    "typeregistry lazy \"x\" created nil",
+		},
+		{
+			factory: func() interface{} { return &nameType{} },
+			want:    "typeregistry lazy \"x\" created \"*typeregistry.nameType\"",
+		},
+	}
+	for i, test := range bad {
+		l.AddLazy("x", test.factory)
+		var paniced string
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					paniced = r.(string)
+				}
+			}()
+			l.New("x")
+		}()
+		if paniced != test.want {
+			t.Errorf("%d New() got panic %q, want %q", i, paniced, test.want)
+		}
+	}
+}
+
+func TestLazy_factory(t *testing.T) {
+	l := Lazy(New())
+	l.AddLazy("*typeregistry.nameType", func() interface{} {
+		return &nameType{}
+	})
+	fail := true
+	l.AddLazy("*typeregistry.unmarshalType", func() interface{} {
+		if fail {
+			panic("not ready")
+		}
+		// Factories can use the registry.
+		l.New("*typeregistry.nameType")
+		return &unmarshalType{}
+	})
+
+	var paniced interface{}
+	func() {
+		defer func() { paniced = recover() }()
+		l.New("*typeregistry.unmarshalType")
+	}()
+	if paniced != "not ready" {
+		t.Errorf("New() got panic %v, want not ready", paniced)
+	}
+
+	fail = false
+	if got := l.New("*typeregistry.unmarshalType"); !reflect.DeepEqual(got, &unmarshalType{}) {
+		t.Errorf("New() after a failed factory got %#v, want %#v", got, &unmarshalType{})
+	}
+}
+
+func TestLazy_concurrent(t *testing.T) {
+	l := Lazy(New())
+	name := l.Add(&nameType{})
+	l.AddLazy("*typeregistry.unmarshalType", func() interface{} {
+		return &unmarshalType{}
+	})
+
+	started := make(chan bool)
+	stop := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		l.New(name)
+		close(started)
+		for {
+			select {
+			case <-stop:
+				close(done)
+				return
+			default:
+				l.New(name)
+			}
+		}
+	}()
+	<-started
+	l.New("*typeregistry.unmarshalType")
+	close(stop)
+	<-done
+}
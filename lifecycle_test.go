@@ -0,0 +1,51 @@
+package typeregistry
+
+import "testing"
+
+func TestLifecycle(t *testing.T) {
+	l := Building(New())
+	name := l.Add(&unmarshalType{})
+
+	panics := func(f func()) (paniced string) {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		f()
+		return ""
+	}
+
+	building := []struct {
+		f    func()
+		want string
+	}{
+		{f: func() { l.New(name) }, want: "typeregistry cannot New *typeregistry.unmarshalType before Freeze"},
+		{f: func() { l.Marshal(&unmarshalType{}) }, want: "typeregistry cannot Marshal *typeregistry.unmarshalType before Freeze"},
+		{f: func() { l.Unmarshal(name, nil, NoSetup) }, want: "typeregistry cannot Unmarshal *typeregistry.unmarshalType before Freeze"},
+		{f: l.MustBeFrozen, want: "typeregistry is not frozen"},
+	}
+	for i, test := range building {
+		if got := panics(test.f); got != test.want {
+			t.Errorf("%d building got panic %q, want %q", i, got, test.want)
+		}
+	}
+
+	l.Freeze()
+	l.Freeze()
+	frozen := []struct {
+		f    func()
+		want string
+	}{
+		{f: func() { l.New(name) }, want: ""},
+		{f: func() { l.Marshal(&unmarshalType{}) }, want: ""},
+		{f: func() { l.Unmarshal(name, nil, NoSetup) }, want: ""},
+		{f: l.MustBeFrozen, want: ""},
+		{f: func() { l.Add(&nameType{}) }, want: "typeregistry cannot add *typeregistry.nameType after Freeze"},
+	}
+	for i, test := range frozen {
+		if got := panics(test.f); got != test.want {
+			t.Errorf("%d frozen got panic %q, want %q", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package typeregistry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limit holds the guardrails for unmarshaling one type. A zero field means
+// no limit. Each rate allows bursts of up to one second's worth, and always
+// at least one item.
+type Limit struct {
+	// PerSecond is the number of unmarshals allowed per second.
+	PerSecond float64
+	// BytesPerSecond is the number of bytes of data allowed per second.
+	BytesPerSecond float64
+}
+
+// LimitError is returned by LimitedRegistry.Unmarshal when data is refused
+// because its type is over a limit. It is retryable, unless the data is
+// Permanent: larger than a second's worth of bytes, so it can never fit.
+type LimitError struct {
+	Name      string
+	Reason    string
+	Permanent bool
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("typeregistry %s is over its limit of %s", e.Name, e.Reason)
+}
+
+// Temporary reports whether the error is not Permanent, so that IsRetryable
+// treats it as transient.
+func (e *LimitError) Temporary() bool { return !e.Permanent }
+
+// LimitedRegistry is a Registry that refuses to unmarshal types faster than
+// their limits allow, so that a flood of one type cannot starve the others.
+type LimitedRegistry struct {
+	Registry Registry
+	// OnBreach, if set, is called each time data is refused.
+	OnBreach func(*LimitError)
+
+	mu      sync.Mutex
+	limits  map[string]Limit
+	buckets map[string]*limitBuckets
+	now     func() time.Time
+}
+
+type limitBuckets struct {
+	count, bytes float64
+	last         time.Time
+}
+
+// Limited wraps a registry with per-type limits, set by SetLimit.
+func Limited(r Registry, onBreach func(*LimitError)) *LimitedRegistry {
+	return &LimitedRegistry{
+		Registry: r,
+		OnBreach: onBreach,
+		limits:   make(map[string]Limit),
+		buckets:  make(map[string]*limitBuckets),
+		now:      time.Now,
+	}
+}
+
+// SetLimit sets the limit for unmarshaling the type registered as name.
+func (l *LimitedRegistry) SetLimit(name string, limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[name] = limit
+	l.buckets[name] = &limitBuckets{
+		count: burst(limit.PerSecond),
+		bytes: limit.BytesPerSecond,
+		last:  l.now(),
+	}
+}
+
+// Add puts a new type in the registry.
+func (l *LimitedRegistry) Add(o interface{}) string {
+	return l.Registry.Add(o)
+}
+
+// New instantiates a type by name.
+func (l *LimitedRegistry) New(name string) interface{} {
+	return l.Registry.New(name)
+}
+
+// Marshal encodes a type.
+func (l *LimitedRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return l.marshalWith(l, o)
+}
+
+func (l *LimitedRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(l.Registry, outer, o)
+}
+
+func (l *LimitedRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(l.Registry, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name, or returns a *LimitError without decoding
+// if the type is over its limit.
+func (l *LimitedRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return l.unmarshalWith(l, name, data, setup)
+}
+
+func (l *LimitedRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if err := l.take(name, len(data)); err != nil {
+		if l.OnBreach != nil {
+			l.OnBreach(err)
+		}
+		return nil, err
+	}
+	return unmarshalVia(l.Registry, outer, name, data, setup)
+}
+
+func (l *LimitedRegistry) has(name string) bool {
+	return knows(l.Registry, name)
+}
+
+func (l *LimitedRegistry) take(name string, size int) *LimitError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.limits[name]
+	if !ok {
+		return nil
+	}
+	b := l.buckets[name]
+	now := l.now()
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.count = refill(b.count, limit.PerSecond, burst(limit.PerSecond), elapsed)
+	b.bytes = refill(b.bytes, limit.BytesPerSecond, limit.BytesPerSecond, elapsed)
+	if limit.BytesPerSecond > 0 && float64(size) > limit.BytesPerSecond {
+		return &LimitError{Name: name, Reason: fmt.Sprintf("%g bytes per second", limit.BytesPerSecond), Permanent: true}
+	}
+	if limit.PerSecond > 0 && b.count < 1 {
+		return &LimitError{Name: name, Reason: fmt.Sprintf("%g per second", limit.PerSecond)}
+	}
+	if limit.BytesPerSecond > 0 && b.bytes < float64(size) {
+		return &LimitError{Name: name, Reason: fmt.Sprintf("%g bytes per second", limit.BytesPerSecond)}
+	}
+	b.count--
+	b.bytes -= float64(size)
+	return nil
+}
+
+// refill adds rate*elapsed to a bucket, up to capacity.
+func refill(level, rate, capacity, elapsed float64) float64 {
+	level += rate * elapsed
+	if level > capacity {
+		return capacity
+	}
+	return level
+}
+
+// burst returns the capacity of a bucket of items, one second's worth but
+// never less than one item.
+func burst(rate float64) float64 {
+	if rate < 1 {
+		return 1
+	}
+	return rate
+}
@@ -0,0 +1,66 @@
+package typeregistry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimited(t *testing.T) {
+	now := time.Unix(0, 0)
+	var breaches []*LimitError
+	l := Limited(New(), func(e *LimitError) {
+		breaches = append(breaches, e)
+	})
+	l.now = func() time.Time { return now }
+	name := l.Add(&unmarshalType{})
+	other := l.Add(&nameType{})
+	l.SetLimit(name, Limit{PerSecond: 2, BytesPerSecond: 5})
+
+	tests := []struct {
+		wait      time.Duration
+		name      string
+		data      string
+		err       bool
+		permanent bool
+	}{
+		{name: name, data: "ab"},
+		{name: name, data: "ab"},
+		{name: name, data: "", err: true},
+		{name: other, data: "unlimited"},
+		{wait: time.Second / 2, name: name, data: ""},
+		{wait: time.Second, name: name, data: "abcdef", err: true, permanent: true},
+		{name: name, data: "abcde"},
+	}
+	for i, test := range tests {
+		now = now.Add(test.wait)
+		_, err := l.Unmarshal(test.name, []byte(test.data), NoSetup)
+		if (err != nil) != test.err {
+			t.Errorf("%d Unmarshal() error got %v, want error %t", i, err, test.err)
+		}
+		if err != nil && IsRetryable(err) == test.permanent {
+			t.Errorf("%d Unmarshal() error %v retryable got %t, want %t", i, err, IsRetryable(err), !test.permanent)
+		}
+	}
+	if len(breaches) != 2 {
+		t.Errorf("OnBreach got %d calls, want 2", len(breaches))
+	}
+}
+
+func TestLimited_slow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := Limited(New(), nil)
+	l.now = func() time.Time { return now }
+	name := l.Add(&unmarshalType{})
+	l.SetLimit(name, Limit{PerSecond: 0.5})
+
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if _, err := l.Unmarshal(name, nil, NoSetup); err == nil {
+			allowed++
+		}
+		now = now.Add(time.Second)
+	}
+	if allowed != 5 {
+		t.Errorf("Unmarshal() at 0.5 per second allowed %d of 10, want 5", allowed)
+	}
+}
@@ -0,0 +1,75 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NameSelector is implemented by types added under several names with
+// AddNames, to choose the name that each value is marshaled as, such as the
+// topic it is being sent to.
+type NameSelector interface {
+	SelectName() string
+}
+
+// AddNamed puts a type in the registry under name, instead of the name Add
+// would derive. It returns an error if the type cannot be registered, or if
+// name is empty or already registered as a different type.
+func (r TypeRegistry) AddNamed(name string, o interface{}) error {
+	if err := checkAdd(o); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("typeregistry cannot add %T with an empty name", o)
+	}
+	t := reflect.TypeOf(o)
+	if err := r.checkName(name, t); err != nil {
+		return err
+	}
+	record(t)
+	r[name] = t
+	return nil
+}
+
+// AddNames puts a type in the registry under each of names, instead of the
+// name Add would derive, so that it can be instantiated and unmarshaled by
+// any of them. Values are marshaled under the first of their names in sorted
+// order unless they implement NameSelector. If the type cannot be registered,
+// it panics.
+func (r TypeRegistry) AddNames(o interface{}, names ...string) {
+	mustAdd(o)
+	t := reflect.TypeOf(o)
+	record(t)
+	for _, name := range names {
+		r[name] = t
+	}
+}
+
+// marshalName returns the name to marshal o as. A NameSelector must select a
+// name that its type is registered as. Otherwise it is the name derived by
+// Add, or if the type was only added under other names, the first of them.
+func (r TypeRegistry) marshalName(o interface{}) (string, error) {
+	s, ok := o.(NameSelector)
+	if !ok {
+		name := r.name(o)
+		if _, ok := r[name]; ok {
+			return name, nil
+		}
+		t := reflect.TypeOf(o)
+		found := ""
+		for n, other := range r {
+			if other == t && (found == "" || n < found) {
+				found = n
+			}
+		}
+		if found != "" {
+			return found, nil
+		}
+		return name, nil
+	}
+	name := s.SelectName()
+	if r[name] != reflect.TypeOf(o) {
+		return name, fmt.Errorf("typeregistry %T is not registered as %#v", o, name)
+	}
+	return name, nil
+}
@@ -0,0 +1,96 @@
+package typeregistry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type topicType struct {
+	JSON
+	Topic string
+}
+
+func (t topicType) SelectName() string {
+	return t.Topic
+}
+
+func TestTypeRegistry_AddNames(t *testing.T) {
+	r := New()
+	r.AddNames(topicType{}, "account.created", "account.updated")
+	if _, ok := r["typeregistry.topicType"]; ok || len(r) != 2 {
+		t.Errorf("AddNames() got %#v, want only the given names", r)
+	}
+
+	tests := []struct {
+		o    topicType
+		name string
+		err  bool
+	}{
+		{o: topicType{Topic: "account.created"}, name: "account.created"},
+		{o: topicType{Topic: "account.updated"}, name: "account.updated"},
+		{o: topicType{Topic: "account.deleted"}, name: "account.deleted", err: true},
+	}
+	for i, test := range tests {
+		name, data, err := r.Marshal(test.o)
+		if name != test.name {
+			t.Errorf("%d Marshal() name got %s, want %s", i, name, test.name)
+		}
+		if test.err {
+			if err == nil {
+				t.Errorf("%d Marshal() wants error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.o) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.o)
+		}
+	}
+}
+
+func TestTypeRegistry_AddNamed(t *testing.T) {
+	r := New()
+	tests := []struct {
+		name string
+		o    interface{}
+		err  string
+	}{
+		{name: "event.v1", o: &nameType{}},
+		{name: "legacy-event", o: &nameType{}},
+		{name: "event.v1", o: &nameType{}},
+		{name: "event.v1", o: &nothingType{}, err: `typeregistry cannot add *typeregistry.nothingType as "event.v1", it is already *typeregistry.nameType`},
+		{name: "", o: &nothingType{}, err: "typeregistry cannot add *typeregistry.nothingType with an empty name"},
+		{name: "nil", o: nil, err: "typeregistry cannot add nil"},
+	}
+	for i, test := range tests {
+		err := r.AddNamed(test.name, test.o)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if test.err == "" && got != "" || !strings.HasPrefix(got, test.err) {
+			t.Errorf("%d AddNamed() got error %q, want %q", i, got, test.err)
+		}
+	}
+	if len(r) != 2 {
+		t.Errorf("AddNamed() got %#v, want only the given names", r)
+	}
+
+	name, _, err := r.Marshal(&nameType{Name: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if name != "event.v1" {
+		t.Errorf("Marshal() name got %s, want event.v1", name)
+	}
+	if got := r.New("legacy-event"); !reflect.DeepEqual(got, &nameType{}) {
+		t.Errorf("New() got %#v, want %#v", got, &nameType{})
+	}
+}
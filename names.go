@@ -0,0 +1,341 @@
+package typeregistry
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NameMapper translates the names used inside a registry to the names that
+// are seen outside of it, and back again.
+type NameMapper interface {
+	// MapName returns the external name for a registered name.
+	MapName(name string) string
+	// UnmapName returns the registered name for an external name, or false if
+	// the external name is unknown.
+	UnmapName(external string) (string, bool)
+}
+
+// MappedRegistry is a Registry whose names are translated by a NameMapper.
+// Add and Marshal return external names, New and Unmarshal accept them.
+type MappedRegistry struct {
+	Registry Registry
+	Mapper   NameMapper
+}
+
+// MapNames wraps a registry so that all names passing in and out of it are
+// translated by m.
+func MapNames(r Registry, m NameMapper) *MappedRegistry {
+	return &MappedRegistry{Registry: r, Mapper: m}
+}
+
+// Add puts a new type in the underlying registry and returns its external
+// name. If the mapper cannot accept the name, it panics.
+func (m *MappedRegistry) Add(o interface{}) string {
+	name := m.Registry.Add(o)
+	if a, ok := m.Mapper.(nameAdder); ok {
+		if err := a.addName(name); err != nil {
+			panic(err.Error())
+		}
+	}
+	return m.Mapper.MapName(name)
+}
+
+// nameAdder is implemented by mappers that must be told about each name added
+// to the registry before they can unmap it.
+type nameAdder interface {
+	addName(name string) error
+}
+
+// ExportNameMap returns a copy of the registered name to external name table
+// of the NameMap translating m, or nil if m is not translated by a NameMap.
+func (m *MappedRegistry) ExportNameMap() map[string]string {
+	if n, ok := m.Mapper.(*NameMap); ok {
+		return n.ExportNameMap()
+	}
+	return nil
+}
+
+// ImportNameMap adds registered name to external name pairs to the NameMap
+// translating m. It returns an error if m is not translated by a NameMap.
+func (m *MappedRegistry) ImportNameMap(t map[string]string) error {
+	if n, ok := m.Mapper.(*NameMap); ok {
+		return n.ImportNameMap(t)
+	}
+	return fmt.Errorf("typeregistry cannot import names into %T", m.Mapper)
+}
+
+// New instantiates a type by external name. If the name is unknown, it
+// panics.
+func (m *MappedRegistry) New(name string) interface{} {
+	return m.Registry.New(m.unmap(name))
+}
+
+// Marshal encodes a type, returning its external name.
+func (m *MappedRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return m.marshalWith(m, o)
+}
+
+func (m *MappedRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	name, data, err := marshalVia(m.Registry, outer, o)
+	return m.Mapper.MapName(name), data, err
+}
+
+func (m *MappedRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	name, data, err := appendVia(m.Registry, outer, dst, o)
+	return m.Mapper.MapName(name), data, err
+}
+
+// Unmarshal decodes a type by external name. If the name is unknown, it
+// panics.
+func (m *MappedRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return m.unmarshalWith(m, name, data, setup)
+}
+
+func (m *MappedRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return unmarshalVia(m.Registry, outer, m.unmap(name), data, setup)
+}
+
+func (m *MappedRegistry) has(name string) bool {
+	internal, ok := m.Mapper.UnmapName(name)
+	return ok && knows(m.Registry, internal)
+}
+
+func (m *MappedRegistry) unmap(external string) string {
+	if name, ok := m.Mapper.UnmapName(external); ok {
+		return name
+	}
+	panic(fmt.Sprintf("typeregistry does not know %#v", external))
+}
+
+// HashNames returns a NameMapper that replaces each name with a keyed hash of
+// it, so that marshaled data leaving your system does not reveal the package
+// structure of the types it holds. The reverse mapping is built from names,
+// such as the Names of the registry it will translate, and kept in memory
+// only. Names added through a MappedRegistry are learned as they are added.
+func HashNames(key []byte, names ...string) NameMapper {
+	h := &hashMapper{
+		key:   key,
+		names: make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		h.names[h.MapName(name)] = name
+	}
+	return h
+}
+
+type hashMapper struct {
+	key   []byte
+	mu    sync.RWMutex
+	names map[string]string
+}
+
+func (h *hashMapper) MapName(name string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (h *hashMapper) UnmapName(external string) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	name, ok := h.names[external]
+	return name, ok
+}
+
+func (h *hashMapper) addName(name string) error {
+	id := h.MapName(name)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.names[id] = name
+	return nil
+}
+
+// NameMap is a NameMapper backed by an explicit table of registered names to
+// external names, such as the language-neutral identifiers agreed with
+// consumers written in other languages. Registered names that are not in the
+// table pass through unchanged.
+type NameMap struct {
+	mu         sync.RWMutex
+	toExternal map[string]string
+	toInternal map[string]string
+}
+
+// NewNameMap initializes a NameMap that knows names, such as the Names of the
+// registry it will translate. Names added through a MappedRegistry are learned
+// as they are added.
+func NewNameMap(names ...string) *NameMap {
+	n := &NameMap{
+		toExternal: make(map[string]string),
+		toInternal: make(map[string]string),
+	}
+	for _, name := range names {
+		n.toInternal[name] = name
+	}
+	return n
+}
+
+func (n *NameMap) addName(name string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.toExternal[name]; ok {
+		return nil
+	}
+	if other, ok := n.toInternal[name]; ok && other != name {
+		return fmt.Errorf("typeregistry name %#v is already mapped from %#v", name, other)
+	}
+	n.toInternal[name] = name
+	return nil
+}
+
+// ImportNameMap adds the registered name to external name pairs in m. It
+// returns an error, and imports nothing, if an external name would refer to
+// more than one registered name.
+func (n *NameMap) ImportNameMap(m map[string]string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	seen := make(map[string]string, len(m))
+	for name, external := range m {
+		if other, ok := seen[external]; ok {
+			return fmt.Errorf("typeregistry name %#v is mapped from both %#v and %#v", external, other, name)
+		}
+		if other, ok := n.toInternal[external]; ok && other != name {
+			return fmt.Errorf("typeregistry name %#v is already mapped from %#v", external, other)
+		}
+		seen[external] = name
+	}
+	for name, external := range m {
+		if old, ok := n.toExternal[name]; ok {
+			delete(n.toInternal, old)
+		} else if n.toInternal[name] == name {
+			delete(n.toInternal, name)
+		}
+		n.toExternal[name] = external
+		n.toInternal[external] = name
+	}
+	return nil
+}
+
+// ExportNameMap returns a copy of the registered name to external name table.
+func (n *NameMap) ExportNameMap() map[string]string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	m := make(map[string]string, len(n.toExternal))
+	for name, external := range n.toExternal {
+		m[name] = external
+	}
+	return m
+}
+
+// MapName returns the external name for name, or name if it is not mapped.
+func (n *NameMap) MapName(name string) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if external, ok := n.toExternal[name]; ok {
+		return external
+	}
+	return name
+}
+
+// UnmapName returns the registered name for external. It returns false if
+// external is unknown, including a registered name that has been mapped to a
+// different external name.
+func (n *NameMap) UnmapName(external string) (string, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	name, ok := n.toInternal[external]
+	return name, ok
+}
+
+// TransformNames returns a NameMapper that passes each name through fns in
+// order, such as to match an established naming convention. The reverse
+// mapping is built from names, such as the Names of the registry it will
+// translate, and names added through a MappedRegistry are learned as they are
+// added. It returns an error if two names transform to the same name, and the
+// MappedRegistry panics if an added name collides.
+func TransformNames(names []string, fns ...func(string) string) (NameMapper, error) {
+	t := &transformMapper{
+		fns:   fns,
+		names: make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		if err := t.addName(name); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+type transformMapper struct {
+	fns   []func(string) string
+	mu    sync.RWMutex
+	names map[string]string
+}
+
+func (t *transformMapper) MapName(name string) string {
+	for _, fn := range t.fns {
+		name = fn(name)
+	}
+	return name
+}
+
+func (t *transformMapper) UnmapName(external string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, ok := t.names[external]
+	return name, ok
+}
+
+func (t *transformMapper) addName(name string) error {
+	external := t.MapName(name)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if other, ok := t.names[external]; ok && other != name {
+		return fmt.Errorf("typeregistry names %#v and %#v both map to %#v", other, name, external)
+	}
+	t.names[external] = name
+	return nil
+}
+
+// TrimPointer removes the leading "*" from the name of a pointer type.
+func TrimPointer(name string) string {
+	return strings.TrimPrefix(name, "*")
+}
+
+// TrimPrefix returns a function that removes prefix from names.
+func TrimPrefix(prefix string) func(string) string {
+	return func(name string) string {
+		return strings.TrimPrefix(name, prefix)
+	}
+}
+
+// ReplaceSeparator returns a function that replaces every old in names with
+// new.
+func ReplaceSeparator(old, new string) func(string) string {
+	return func(name string) string {
+		return strings.Replace(name, old, new, -1)
+	}
+}
+
+// SnakeCase converts the words of mixed case names to lower case separated by
+// underscores, so "billing.InvoicePaid" becomes "billing.invoice_paid" and
+// "HTTPRequest" becomes "http_request".
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteRune('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
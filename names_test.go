@@ -0,0 +1,244 @@
+package typeregistry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHashNames(t *testing.T) {
+	r := MapNames(New(), HashNames([]byte("secret")))
+	name := r.Add(&unmarshalType{})
+	if strings.Contains(name, "unmarshalType") {
+		t.Errorf("Add() got %s, want an opaque name", name)
+	}
+	if got := HashNames([]byte("other")).MapName("*typeregistry.unmarshalType"); got == name {
+		t.Errorf("MapName() with a different key got the same name %s", got)
+	}
+
+	mname, _, err := r.Marshal(&unmarshalType{})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if mname != name {
+		t.Errorf("Marshal() name got %s, want %s", mname, name)
+	}
+
+	got, err := r.Unmarshal(name, []byte("ok"), NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:ok"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		r.New("*typeregistry.unmarshalType")
+	}()
+	if paniced != "typeregistry does not know \"*typeregistry.unmarshalType\"" {
+		t.Errorf("Expected New() of an unmapped name to panic, got %s", paniced)
+	}
+}
+
+func TestHashNames_names(t *testing.T) {
+	inner := New()
+	inner.Add(&unmarshalType{})
+	sender := MapNames(New(), HashNames([]byte("secret")))
+	name, data, err := sender.Marshal(&unmarshalType{})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+
+	r := MapNames(inner, HashNames([]byte("secret"), inner.Names()...))
+	got, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+}
+
+func TestHashNames_nested(t *testing.T) {
+	r := MapNames(NonNil(New()), HashNames([]byte("secret")))
+	name := r.Add(&sagaType{})
+	r.Add(&unmarshalType{})
+
+	saga := &sagaType{Steps: []interface{}{&unmarshalType{}}}
+	_, data, err := r.Marshal(saga)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if strings.Contains(string(data), "unmarshalType") {
+		t.Errorf("Marshal() got %s, want opaque nested names", data)
+	}
+	got, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&sagaType{Steps: []interface{}{&unmarshalType{Name: "bin:"}}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+}
+
+func TestNameMap(t *testing.T) {
+	m := NewNameMap()
+	if err := m.ImportNameMap(map[string]string{
+		"*typeregistry.unmarshalType": "com.example.Unmarshal",
+	}); err != nil {
+		t.Fatalf("ImportNameMap() wants no error, got: %s", err)
+	}
+	err := m.ImportNameMap(map[string]string{
+		"typeregistry.nothingType": "com.example.Unmarshal",
+	})
+	if err == nil {
+		t.Errorf("ImportNameMap() of a duplicate external name wants error, got none")
+	}
+	want := map[string]string{"*typeregistry.unmarshalType": "com.example.Unmarshal"}
+	if got := m.ExportNameMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportNameMap() got %#v, want %#v", got, want)
+	}
+
+	tests := []struct {
+		t    interface{}
+		want string
+	}{
+		{
+			t:    &unmarshalType{},
+			want: "com.example.Unmarshal",
+		},
+		{
+			t:    nothingType{},
+			want: "typeregistry.nothingType",
+		},
+	}
+	for i, test := range tests {
+		r := MapNames(New(), m)
+		name := r.Add(test.t)
+		if name != test.want {
+			t.Errorf("%d Add(%#v) got %s, want %s", i, test.t, name, test.want)
+		}
+		mname, data, _ := r.Marshal(test.t)
+		if mname != test.want {
+			t.Errorf("%d Marshal(%#v) name got %s, want %s", i, test.t, mname, test.want)
+		}
+		got, err := r.Unmarshal(mname, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal wants no error, got: %s", i, err)
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(test.t) {
+			t.Errorf("%d Unmarshal() got %T, want %T", i, got, test.t)
+		}
+	}
+
+	unknown := []string{
+		"*typeregistry.unmarshalType",
+		"typeregistry.stringType",
+	}
+	for i, external := range unknown {
+		if name, ok := m.UnmapName(external); ok {
+			t.Errorf("%d UnmapName(%s) got %s, want false", i, external, name)
+		}
+	}
+}
+
+func TestMappedRegistry_nameMap(t *testing.T) {
+	r := MapNames(New(), NewNameMap())
+	if err := r.ImportNameMap(map[string]string{
+		"*typeregistry.unmarshalType": "com.example.Unmarshal",
+	}); err != nil {
+		t.Fatalf("ImportNameMap() wants no error, got: %s", err)
+	}
+	if name := r.Add(&unmarshalType{}); name != "com.example.Unmarshal" {
+		t.Errorf("Add() got %s, want %s", name, "com.example.Unmarshal")
+	}
+	want := map[string]string{"*typeregistry.unmarshalType": "com.example.Unmarshal"}
+	if got := r.ExportNameMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportNameMap() got %#v, want %#v", got, want)
+	}
+	if _, err := unmarshalE(r, "*typeregistry.unmarshalType", []byte("ok"), NoSetup); err != (ErrUnknownType{Name: "*typeregistry.unmarshalType"}) {
+		t.Errorf("unmarshalE() of a mapped registered name got %v, want ErrUnknownType", err)
+	}
+
+	h := MapNames(New(), HashNames([]byte("key")))
+	if err := h.ImportNameMap(want); err == nil {
+		t.Errorf("ImportNameMap() without a NameMap wants error, got none")
+	}
+	if got := h.ExportNameMap(); got != nil {
+		t.Errorf("ExportNameMap() without a NameMap got %#v, want nil", got)
+	}
+}
+
+func TestTransformNames(t *testing.T) {
+	fns := []func(string) string{TrimPointer, TrimPrefix("typeregistry."), SnakeCase, ReplaceSeparator("_", "-")}
+	m, err := TransformNames(nil, fns...)
+	if err != nil {
+		t.Fatalf("TransformNames() wants no error, got: %s", err)
+	}
+	r := MapNames(New(), m)
+	name := r.Add(&unmarshalType{})
+	if name != "unmarshal-type" {
+		t.Errorf("Add() got %s, want %s", name, "unmarshal-type")
+	}
+	got, err := r.Unmarshal(name, []byte("ok"), NoSetup)
+	if err != nil {
+		t.Errorf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:ok"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		r.Add(unmarshalType{})
+	}()
+	if paniced != "typeregistry names \"*typeregistry.unmarshalType\" and \"typeregistry.unmarshalType\" both map to \"unmarshal-type\"" {
+		t.Errorf("Expected Add() of a colliding name to panic, got %s", paniced)
+	}
+
+	if _, _, err := r.Marshal(unmarshalType{}); err != nil {
+		t.Errorf("Marshal() of a colliding name wants no error, got: %s", err)
+	}
+	if _, err := TransformNames([]string{"*typeregistry.unmarshalType", "typeregistry.unmarshalType"}, fns...); err == nil {
+		t.Errorf("TransformNames() of colliding names wants error, got none")
+	}
+
+	inner := New()
+	inner.Add(&unmarshalType{})
+	m, err = TransformNames(inner.Names(), fns...)
+	if err != nil {
+		t.Fatalf("TransformNames() wants no error, got: %s", err)
+	}
+	if got, ok := m.UnmapName("unmarshal-type"); !ok || got != "*typeregistry.unmarshalType" {
+		t.Errorf("UnmapName() got %s, %v, want %s", got, ok, "*typeregistry.unmarshalType")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "billing.InvoicePaid", want: "billing.invoice_paid"},
+		{name: "HTTPRequest", want: "http_request"},
+		{name: "userID", want: "user_id"},
+		{name: "V2Event", want: "v2_event"},
+		{name: "already_snake", want: "already_snake"},
+	}
+	for i, test := range tests {
+		if got := SnakeCase(test.name); got != test.want {
+			t.Errorf("%d SnakeCase(%s) got %s, want %s", i, test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NamespacedRegistry is a view of a TypeRegistry whose names are prefixed, so
+// that plugins sharing one registry cannot collide. Types are stored in the
+// registry as prefix/name, and the view uses those prefixed names in and out,
+// so that values written by different namespaces cannot be confused on the
+// wire either.
+type NamespacedRegistry struct {
+	r      TypeRegistry
+	prefix string
+}
+
+// Namespace returns a view of the registry that adds and finds types under
+// prefix.
+func (r TypeRegistry) Namespace(prefix string) *NamespacedRegistry {
+	return &NamespacedRegistry{r: r, prefix: prefix + "/"}
+}
+
+// Add puts a new type in the registry as prefix/name, returning prefix/name.
+// If the type cannot be registered, or another type is already registered as
+// prefix/name, it panics.
+func (n *NamespacedRegistry) Add(o interface{}) string {
+	mustAdd(o)
+	name := n.prefix + n.r.name(o)
+	if err := n.r.AddNamed(name, o); err != nil {
+		panic(err.Error())
+	}
+	return name
+}
+
+// New instantiates a type by prefixed name. If the name is unknown in the
+// namespace, it panics.
+func (n *NamespacedRegistry) New(name string) interface{} {
+	n.mustKnow(name)
+	return n.r.New(name)
+}
+
+// Marshal encodes a type, returning its prefixed name. It returns an error if
+// the type is not registered in the namespace.
+func (n *NamespacedRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return n.marshalWith(n, o)
+}
+
+func (n *NamespacedRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	if u, ok := o.(*Unknown); ok {
+		return u.Name, u.Data, nil
+	}
+	name, err := n.marshalName(o)
+	if err != nil {
+		return name, nil, err
+	}
+	_, data, err := n.r.marshalWith(outer, o)
+	return name, data, err
+}
+
+func (n *NamespacedRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	if _, ok := o.(*Unknown); ok {
+		return appendMarshaled(n, outer, dst, o)
+	}
+	name, err := n.marshalName(o)
+	if err != nil {
+		return name, dst, err
+	}
+	_, data, err := n.r.appendWith(outer, dst, o)
+	return name, data, err
+}
+
+// marshalName returns the prefixed name of o, or an error if o is not
+// registered in the namespace.
+func (n *NamespacedRegistry) marshalName(o interface{}) (string, error) {
+	name := n.prefix + n.r.name(o)
+	if n.r[name] != reflect.TypeOf(o) {
+		return name, fmt.Errorf("typeregistry %T is not registered in namespace %#v", o, n.prefix[:len(n.prefix)-1])
+	}
+	return name, nil
+}
+
+// Unmarshal decodes a type by prefixed name. If the name is unknown in the
+// namespace, it panics.
+func (n *NamespacedRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return n.unmarshalWith(n, name, data, setup)
+}
+
+func (n *NamespacedRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	n.mustKnow(name)
+	return n.r.unmarshalWith(outer, name, data, setup)
+}
+
+func (n *NamespacedRegistry) has(name string) bool {
+	return strings.HasPrefix(name, n.prefix) && n.r.has(name)
+}
+
+func (n *NamespacedRegistry) mustKnow(name string) {
+	if _, ok := n.r[name]; !ok || !strings.HasPrefix(name, n.prefix) {
+		panic(fmt.Sprintf("typeregistry does not know %#v", name))
+	}
+}
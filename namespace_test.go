@@ -0,0 +1,72 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_Namespace(t *testing.T) {
+	r := New()
+	billing := r.Namespace("billing")
+	shipping := r.Namespace("shipping")
+	name := billing.Add(&jsonType{})
+	shipping.Add(&jsonType{})
+
+	want := []string{"billing/*typeregistry.jsonType", "shipping/*typeregistry.jsonType"}
+	if got := r.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() got %v, want %v", got, want)
+	}
+	if name != "billing/*typeregistry.jsonType" {
+		t.Errorf("Add() got %s, want billing/*typeregistry.jsonType", name)
+	}
+
+	gotName, data, err := billing.Marshal(&jsonType{ID: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if gotName != name {
+		t.Errorf("Marshal() name got %s, want %s", gotName, name)
+	}
+	got, err := billing.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&jsonType{ID: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	shippingName, _, err := shipping.Marshal(&jsonType{ID: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if shippingName == gotName {
+		t.Errorf("Marshal() of two namespaces got the same name %s", gotName)
+	}
+	if _, _, err := billing.Marshal(&nameType{}); err == nil || err.Error() != `typeregistry *typeregistry.nameType is not registered in namespace "billing"` {
+		t.Errorf("Marshal() of a type outside the namespace got error %v", err)
+	}
+
+	tests := []struct {
+		f    func()
+		want string
+	}{
+		{f: func() { billing.New("billing/*typeregistry.nameType") }, want: `typeregistry does not know "billing/*typeregistry.nameType"`},
+		{f: func() { billing.New("*typeregistry.jsonType") }, want: `typeregistry does not know "*typeregistry.jsonType"`},
+		{f: func() { billing.Unmarshal("shipping/*typeregistry.jsonType", nil, nil) }, want: `typeregistry does not know "shipping/*typeregistry.jsonType"`},
+		{f: func() { r.Add(&nameType{}); billing.New("*typeregistry.nameType") }, want: `typeregistry does not know "*typeregistry.nameType"`},
+		{f: func() { shipping.Unmarshal(name, nil, nil) }, want: `typeregistry does not know "billing/*typeregistry.jsonType"`},
+	}
+	for i, test := range tests {
+		var paniced string
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					paniced = r.(string)
+				}
+			}()
+			test.f()
+		}()
+		if paniced != test.want {
+			t.Errorf("%d got panic %q, want %q", i, paniced, test.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package typeregistry
+
+// NonNilRegistry is a Registry whose Marshal returns an empty slice in place
+// of nil data, for storage layers that do not accept nil, or that relied on
+// the empty slices some types used to marshal to.
+type NonNilRegistry struct {
+	Registry
+}
+
+// NonNil wraps a registry so that Marshal never returns nil data.
+func NonNil(r Registry) *NonNilRegistry {
+	return &NonNilRegistry{r}
+}
+
+// Marshal encodes a type, returning an empty slice if it has no data.
+func (n *NonNilRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return n.marshalWith(n, o)
+}
+
+func (n *NonNilRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	name, data, err := marshalVia(n.Registry, outer, o)
+	if data == nil {
+		data = []byte{}
+	}
+	return name, data, err
+}
+
+func (n *NonNilRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendMarshaled(n, outer, dst, o)
+}
+
+func (n *NonNilRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return unmarshalVia(n.Registry, outer, name, data, setup)
+}
+
+func (n *NonNilRegistry) has(name string) bool {
+	return knows(n.Registry, name)
+}
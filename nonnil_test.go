@@ -0,0 +1,29 @@
+package typeregistry
+
+import (
+	"testing"
+)
+
+func TestNonNil(t *testing.T) {
+	tests := []struct {
+		o    interface{}
+		want string
+	}{
+		{o: nothingType{}, want: ""},
+		{o: marshalType{Name: "ok"}, want: "bin:ok"},
+	}
+	for i, test := range tests {
+		r := New()
+		r.Add(test.o)
+		_, data, err := NonNil(r).Marshal(test.o)
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if data == nil {
+			t.Errorf("%d Marshal() got nil data, want %#v", i, test.want)
+		}
+		if string(data) != test.want {
+			t.Errorf("%d Marshal() got %#v, want %#v", i, string(data), test.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package typeregistry
+
+// Normalizer is implemented by types that can put themselves in a canonical
+// form, such as sorting slices or rounding times, so that equal values always
+// marshal to the same bytes. Marshal calls Normalize before encoding, so it
+// usually needs a pointer receiver to have any effect.
+type Normalizer interface {
+	Normalize() error
+}
+
+// normalize calls Normalize if o implements Normalizer.
+func normalize(o interface{}) error {
+	if n, ok := o.(Normalizer); ok {
+		return n.Normalize()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package typeregistry
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type normalType struct {
+	JSON
+	Tags []string
+}
+
+func (n *normalType) Normalize() error {
+	if len(n.Tags) > 3 {
+		return errors.New("too many tags")
+	}
+	sort.Strings(n.Tags)
+	return nil
+}
+
+func (n *normalType) MarshalAppend(dst []byte) ([]byte, error) {
+	return append(dst, strings.Join(n.Tags, ",")...), nil
+}
+
+func TestNormalizer(t *testing.T) {
+	tests := []struct {
+		o    *normalType
+		want string
+		err  bool
+	}{
+		{o: &normalType{Tags: []string{"b", "c", "a"}}, want: `{"Tags":["a","b","c"]}`},
+		{o: &normalType{Tags: []string{"a", "b", "c", "d"}}, err: true},
+	}
+	for i, test := range tests {
+		r := New()
+		_, data, err := r.Marshal(test.o)
+		if test.err {
+			if err == nil {
+				t.Errorf("%d Marshal() wants error, got none", i)
+			}
+			if _, _, err := r.MarshalAppend(nil, test.o); err == nil {
+				t.Errorf("%d MarshalAppend() wants error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%d Marshal() got %s, want %s", i, data, test.want)
+		}
+	}
+
+	o := &normalType{Tags: []string{"b", "a"}}
+	_, data, err := New().MarshalAppend(nil, o)
+	if err != nil {
+		t.Errorf("MarshalAppend() wants no error, got: %s", err)
+	}
+	// The JSON encoding comes before AppendMarshaler, as in Marshal.
+	if want := `{"Tags":["a","b"]}`; string(data) != want {
+		t.Errorf("MarshalAppend() got %s, want %s", data, want)
+	}
+}
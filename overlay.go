@@ -0,0 +1,80 @@
+package typeregistry
+
+// OverlayRegistry is a Registry that looks up types in its own overrides
+// before falling back to a parent registry, which it never changes. Combined
+// with NewContext it gives each request its own view of a shared registry,
+// such as to try a new version of a type on a fraction of traffic.
+type OverlayRegistry struct {
+	Parent    Registry
+	Overrides TypeRegistry
+}
+
+// Overlay returns a registry that prefers the types in overrides to those in
+// parent.
+func Overlay(parent Registry, overrides TypeRegistry) *OverlayRegistry {
+	return &OverlayRegistry{Parent: parent, Overrides: overrides}
+}
+
+// With returns a registry that prefers the types in overrides to those in r.
+func (r TypeRegistry) With(overrides TypeRegistry) *OverlayRegistry {
+	return Overlay(r, overrides)
+}
+
+// With returns a registry that prefers the types in overrides to those in o,
+// leaving o unchanged.
+func (o *OverlayRegistry) With(overrides TypeRegistry) *OverlayRegistry {
+	return Overlay(o, overrides)
+}
+
+// Add puts a new type in the overrides.
+func (o *OverlayRegistry) Add(v interface{}) string {
+	return o.Overrides.Add(v)
+}
+
+// New instantiates a type by name from the overrides, or else the parent.
+func (o *OverlayRegistry) New(name string) interface{} {
+	if _, ok := o.Overrides[name]; ok {
+		return o.Overrides.New(name)
+	}
+	return o.Parent.New(name)
+}
+
+// Marshal encodes a type with the overrides if they hold its type, or else
+// the parent.
+func (o *OverlayRegistry) Marshal(v interface{}) (string, []byte, error) {
+	return o.marshalWith(o, v)
+}
+
+func (o *OverlayRegistry) marshalWith(outer Registry, v interface{}) (string, []byte, error) {
+	if v != nil {
+		if _, ok := o.Overrides[o.Overrides.name(v)]; ok {
+			return o.Overrides.marshalWith(outer, v)
+		}
+	}
+	return marshalVia(o.Parent, outer, v)
+}
+
+func (o *OverlayRegistry) appendWith(outer Registry, dst []byte, v interface{}) (string, []byte, error) {
+	if v != nil {
+		if _, ok := o.Overrides[o.Overrides.name(v)]; ok {
+			return o.Overrides.appendWith(outer, dst, v)
+		}
+	}
+	return appendVia(o.Parent, outer, dst, v)
+}
+
+// Unmarshal decodes a type by name with the overrides, or else the parent.
+func (o *OverlayRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return o.unmarshalWith(o, name, data, setup)
+}
+
+func (o *OverlayRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if _, ok := o.Overrides[name]; ok {
+		return o.Overrides.unmarshalWith(outer, name, data, setup)
+	}
+	return unmarshalVia(o.Parent, outer, name, data, setup)
+}
+
+func (o *OverlayRegistry) has(name string) bool {
+	return o.Overrides.has(name) || knows(o.Parent, name)
+}
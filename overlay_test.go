@@ -0,0 +1,48 @@
+package typeregistry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	parent := New()
+	parent.Add(&unmarshalType{})
+	parent.Add(&nameType{})
+
+	overrides := New()
+	overrides["*typeregistry.unmarshalType"] = reflect.TypeOf(&unmarshalV2Type{})
+	ctx := NewContext(context.Background(), parent)
+	r, _ := FromContext(ctx)
+	ctx = NewContext(ctx, Overlay(r, overrides))
+	o, _ := FromContext(ctx)
+
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{name: "*typeregistry.unmarshalType", want: &unmarshalV2Type{Name: "v2:ok"}},
+		{name: "*typeregistry.nameType", want: &nameType{}},
+	}
+	for i, test := range tests {
+		got, err := o.Unmarshal(test.name, []byte("ok"), NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.want)
+		}
+	}
+
+	added := o.Add(&nothingType{})
+	if _, ok := parent[added]; ok {
+		t.Errorf("Add() changed the parent registry")
+	}
+	if got := o.New(added); !reflect.DeepEqual(got, &nothingType{}) {
+		t.Errorf("New() got %#v, want %#v", got, &nothingType{})
+	}
+	if name, _, _ := o.Marshal(&nameType{}); name != "*typeregistry.nameType" {
+		t.Errorf("Marshal() name got %s, want %s", name, "*typeregistry.nameType")
+	}
+}
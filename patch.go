@@ -0,0 +1,332 @@
+package typeregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Patch applies a JSON Merge Patch (RFC 7386), or a JSON Patch (RFC 6902) if
+// the patch is an array, to a registered value, which must be a pointer. The
+// value is encoded with its registered encoding, patched, and decoded back, so
+// the patch refers to fields as they are marshaled, such as a time.Time
+// tagged "unix" as seconds. A type that the registry does not encode is
+// encoded with encoding/json, and Patch returns an error for any type whose
+// encoding is not JSON. Exported fields are replaced by their patched values,
+// while unexported fields and fields ignored by encoding/json, such as
+// collaborators set by a SetupFunc, are left alone.
+func (r TypeRegistry) Patch(o interface{}, patch []byte) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("typeregistry cannot patch non-pointer %T", o)
+	}
+	if !r.hasType(v.Type()) {
+		return ErrUnknownType{Name: r.name(o)}
+	}
+	e, err := patchEncoding(r.name(o), o)
+	if err != nil {
+		return err
+	}
+	defer trace("Patch", v.Type())()
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return err
+	}
+	data, err := r.marshalPatch(o, e)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if ops, ok := p.([]interface{}); ok {
+		doc, err = jsonPatch(doc, ops)
+	} else {
+		doc = mergePatch(doc, p)
+	}
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	patched := reflect.New(v.Elem().Type())
+	if err := r.unmarshalPatch(patched.Interface(), data, e); err != nil {
+		return err
+	}
+	dst, src := v.Elem(), patched.Elem()
+	if dst.Kind() != reflect.Struct {
+		dst.Set(src)
+		return nil
+	}
+	for i := 0; i < dst.NumField(); i++ {
+		f := dst.Type().Field(i)
+		if f.PkgPath != "" || f.Tag.Get("json") == "-" {
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+	}
+	return nil
+}
+
+// patchEncoding returns the encoding that o is both marshaled and unmarshaled
+// with, or an error if that is not JSON.
+func patchEncoding(name string, o interface{}) (Encoding, error) {
+	e, u := marshalEncoding(o), unmarshalEncoding(o)
+	for _, enc := range []Encoding{e, u} {
+		if enc != 0 && enc != EncodingJSON && enc != EncodingJSONMarshaler {
+			return 0, fmt.Errorf("typeregistry cannot patch %s, its encoding %s is not JSON", name, enc)
+		}
+	}
+	if e != u {
+		return 0, fmt.Errorf("typeregistry cannot patch %s, it is marshaled as %s but unmarshaled as %s", name, e, u)
+	}
+	return e, nil
+}
+
+// marshalPatch encodes o with e, or with encoding/json if e is 0.
+func (r TypeRegistry) marshalPatch(o interface{}, e Encoding) ([]byte, error) {
+	if e == 0 {
+		return json.Marshal(o)
+	}
+	return r.marshalAs(r, o, e)
+}
+
+// unmarshalPatch decodes data into instance with e, or with encoding/json if
+// e is 0.
+func (r TypeRegistry) unmarshalPatch(instance interface{}, data []byte, e Encoding) error {
+	if e == 0 {
+		return json.Unmarshal(data, instance)
+	}
+	_, err := r.unmarshalAs(r, instance, data, e)
+	return err
+}
+
+// mergePatch implements the MergePatch function of RFC 7386.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// jsonPatch applies the operations of an RFC 6902 JSON Patch to doc in order,
+// returning the patched document. It returns an error if any operation fails,
+// including a failed test.
+func jsonPatch(doc interface{}, ops []interface{}) (interface{}, error) {
+	for _, op := range ops {
+		m, ok := op.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("typeregistry patch operation %v is not an object", op)
+		}
+		name, _ := m["op"].(string)
+		path, err := patchPointer(m, "path")
+		if err != nil {
+			return nil, err
+		}
+		value, hasValue := m["value"]
+		if !hasValue && (name == "add" || name == "replace" || name == "test") {
+			return nil, fmt.Errorf("typeregistry patch operation %#v has no value", name)
+		}
+		switch name {
+		case "add":
+			doc, err = addAt(doc, path, value)
+		case "remove":
+			doc, _, err = removeAt(doc, path)
+		case "replace":
+			if doc, _, err = removeAt(doc, path); err == nil {
+				doc, err = addAt(doc, path, value)
+			}
+		case "move", "copy":
+			var from []string
+			if from, err = patchPointer(m, "from"); err != nil {
+				return nil, err
+			}
+			if name == "move" {
+				doc, value, err = removeAt(doc, from)
+			} else if value, err = getAt(doc, from); err == nil {
+				value = copyJSON(value)
+			}
+			if err == nil {
+				doc, err = addAt(doc, path, value)
+			}
+		case "test":
+			var got interface{}
+			if got, err = getAt(doc, path); err == nil && !reflect.DeepEqual(got, value) {
+				err = fmt.Errorf("typeregistry patch test of %#v failed", m["path"])
+			}
+		default:
+			err = fmt.Errorf("typeregistry patch operation %#v is unknown", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// patchPointer returns the tokens of the JSON Pointer (RFC 6901) held by key.
+func patchPointer(op map[string]interface{}, key string) ([]string, error) {
+	s, ok := op[key].(string)
+	if !ok {
+		return nil, fmt.Errorf("typeregistry patch operation %#v has no %s", op["op"], key)
+	}
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("typeregistry patch pointer %#v does not start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// getAt returns the value at path in doc.
+func getAt(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		var err error
+		if doc, err = childOf(doc, token); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// addAt adds value at path in doc, inserting it into arrays, and returns the
+// new document.
+func addAt(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateAt(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+			return p, nil
+		case []interface{}:
+			i := len(p)
+			if token != "-" {
+				var err error
+				if i, err = arrayIndex(token, len(p)+1); err != nil {
+					return nil, err
+				}
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("typeregistry patch cannot add %#v to %T", token, parent)
+	})
+}
+
+// removeAt removes the value at path in doc, and returns the new document and
+// the removed value.
+func removeAt(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("typeregistry patch cannot remove the whole value")
+	}
+	var removed interface{}
+	doc, err := updateAt(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		var err error
+		if removed, err = childOf(parent, token); err != nil {
+			return nil, err
+		}
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			i, _ := arrayIndex(token, len(p))
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return parent, nil
+	})
+	return doc, removed, err
+}
+
+// updateAt replaces the container holding the last token of path with the
+// result of calling f with it and that token, and returns the new document.
+func updateAt(doc interface{}, path []string, f func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return f(doc, path[0])
+	}
+	child, err := childOf(doc, path[0])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = updateAt(child, path[1:], f); err != nil {
+		return nil, err
+	}
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		d[path[0]] = child
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(d))
+		d[i] = child
+	}
+	return doc, nil
+}
+
+// childOf returns the member or element of doc named by token.
+func childOf(doc interface{}, token string) (interface{}, error) {
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		if v, ok := d[token]; ok {
+			return v, nil
+		}
+	case []interface{}:
+		i, err := arrayIndex(token, len(d))
+		if err != nil {
+			return nil, err
+		}
+		return d[i], nil
+	}
+	return nil, fmt.Errorf("typeregistry patch path %#v does not exist", token)
+}
+
+// arrayIndex parses token as an index less than n.
+func arrayIndex(token string, n int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= n || token != strconv.Itoa(i) {
+		return 0, fmt.Errorf("typeregistry patch index %#v is out of range", token)
+	}
+	return i, nil
+}
+
+// copyJSON returns a deep copy of a value decoded by encoding/json.
+func copyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = copyJSON(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = copyJSON(e)
+		}
+		return a
+	}
+	return v
+}
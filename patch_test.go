@@ -0,0 +1,114 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type patchType struct {
+	Name    string
+	Tags    []string
+	Limits  map[string]int
+	Skipped string `json:"-"`
+	svc     *nameType
+}
+
+func TestTypeRegistry_Patch(t *testing.T) {
+	svc := &nameType{}
+	tests := []struct {
+		patch string
+		want  *patchType
+	}{
+		{
+			patch: `{}`,
+			want:  &patchType{Name: "a", Tags: []string{"x"}, Limits: map[string]int{"a": 1, "b": 2}, Skipped: "s", svc: svc},
+		},
+		{
+			patch: `{"Name": "b", "Tags": ["y", "z"]}`,
+			want:  &patchType{Name: "b", Tags: []string{"y", "z"}, Limits: map[string]int{"a": 1, "b": 2}, Skipped: "s", svc: svc},
+		},
+		{
+			patch: `{"Name": null, "Limits": {"a": null, "c": 3}}`,
+			want:  &patchType{Tags: []string{"x"}, Limits: map[string]int{"b": 2, "c": 3}, Skipped: "s", svc: svc},
+		},
+		{
+			patch: `{"Tags": null, "Limits": null}`,
+			want:  &patchType{Name: "a", Skipped: "s", svc: svc},
+		},
+		{
+			patch: `[{"op": "replace", "path": "/Name", "value": "b"}, {"op": "add", "path": "/Tags/0", "value": "w"}, {"op": "add", "path": "/Tags/-", "value": "y"}]`,
+			want:  &patchType{Name: "b", Tags: []string{"w", "x", "y"}, Limits: map[string]int{"a": 1, "b": 2}, Skipped: "s", svc: svc},
+		},
+		{
+			patch: `[{"op": "remove", "path": "/Limits/a"}, {"op": "copy", "from": "/Limits", "path": "/Limits~1b"}, {"op": "move", "from": "/Limits/b", "path": "/Limits/c"}]`,
+			want:  &patchType{Name: "a", Tags: []string{"x"}, Limits: map[string]int{"c": 2}, Skipped: "s", svc: svc},
+		},
+		{
+			patch: `[{"op": "test", "path": "/Name", "value": "a"}, {"op": "remove", "path": "/Tags/0"}]`,
+			want:  &patchType{Name: "a", Tags: []string{}, Limits: map[string]int{"a": 1, "b": 2}, Skipped: "s", svc: svc},
+		},
+	}
+	r := New()
+	r.Add(&patchType{})
+	for i, test := range tests {
+		o := &patchType{Name: "a", Tags: []string{"x"}, Limits: map[string]int{"a": 1, "b": 2}, Skipped: "s", svc: svc}
+		if err := r.Patch(o, []byte(test.patch)); err != nil {
+			t.Errorf("%d Patch() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(o, test.want) {
+			t.Errorf("%d Patch() got %#v, want %#v", i, o, test.want)
+		}
+	}
+
+	errs := []struct {
+		o     interface{}
+		patch string
+	}{
+		{o: patchType{}, patch: `{}`},
+		{o: &nameType{}, patch: `{}`},
+		{o: &patchType{}, patch: `{`},
+		{o: &patchType{}, patch: `{"Name": 1}`},
+		{o: &patchType{Name: "a"}, patch: `[{"op": "test", "path": "/Name", "value": "b"}]`},
+		{o: &patchType{}, patch: `[{"op": "remove", "path": "/Tags/0"}]`},
+		{o: &patchType{}, patch: `[{"op": "replace", "path": "/Nope", "value": 1}]`},
+		{o: &patchType{}, patch: `[{"op": "add", "path": "Name", "value": "a"}]`},
+		{o: &patchType{}, patch: `[{"op": "swap", "path": "/Name"}]`},
+		{o: &unmarshalType{}, patch: `{}`},
+	}
+	for i, test := range errs {
+		if err := r.Patch(test.o, []byte(test.patch)); err == nil {
+			t.Errorf("%d Patch(%#v, %s) wants error, got none", i, test.o, test.patch)
+		}
+	}
+}
+
+func TestTypeRegistry_Patch_encoding(t *testing.T) {
+	r := New()
+	r.Add(&tagType{})
+	o := &tagType{At: time.Unix(10, 0).UTC(), Key: []byte("k")}
+	if err := r.Patch(o, []byte(`{"at": 60}`)); err != nil {
+		t.Fatalf("Patch() wants no error, got: %s", err)
+	}
+	if want := (&tagType{At: time.Unix(60, 0).UTC(), Key: []byte("k")}); !reflect.DeepEqual(o, want) {
+		t.Errorf("Patch() got %#v, want %#v", o, want)
+	}
+
+	r = New()
+	if err := r.AddNamed("patch", &patchType{}); err != nil {
+		t.Fatalf("AddNamed() wants no error, got: %s", err)
+	}
+	p := &patchType{}
+	if err := r.Patch(p, []byte(`{"Name": "b"}`)); err != nil {
+		t.Fatalf("Patch() of a named type wants no error, got: %s", err)
+	}
+	if p.Name != "b" {
+		t.Errorf("Patch() got %#v, want Name b", p)
+	}
+
+	r.Add(&unmarshalType{})
+	err := r.Patch(&unmarshalType{}, []byte(`{}`))
+	if want := "typeregistry cannot patch *typeregistry.unmarshalType, its encoding custom is not JSON"; err == nil || err.Error() != want {
+		t.Errorf("Patch() of a custom type got error %v, want %s", err, want)
+	}
+}
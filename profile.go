@@ -0,0 +1,55 @@
+package typeregistry
+
+// ProfileOption limits which profiles a type is added in by a Profiled
+// registry. It reports whether the type should be added in the active
+// profile.
+type ProfileOption func(active string) bool
+
+// OnlyIn adds a type only when the active profile is one of profiles.
+func OnlyIn(profiles ...string) ProfileOption {
+	return func(active string) bool {
+		for _, p := range profiles {
+			if p == active {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Profiled wraps a registry so that some types, such as test doubles or
+// experimental types, can be added in one place but are only registered, and
+// so can only be instantiated, when their profile is active.
+type Profiled struct {
+	Registry
+	// Active is the name of the active profile, such as "production".
+	Active string
+}
+
+// AddIn puts a new type in the registry if every option allows the active
+// profile. It returns the name that it was registered as, or "" if it was
+// not added. Add, without options, always adds the type.
+func (p Profiled) AddIn(o interface{}, opts ...ProfileOption) string {
+	for _, allowed := range opts {
+		if !allowed(p.Active) {
+			return ""
+		}
+	}
+	return p.Registry.Add(o)
+}
+
+func (p Profiled) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(p.Registry, outer, o)
+}
+
+func (p Profiled) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(p.Registry, outer, dst, o)
+}
+
+func (p Profiled) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return unmarshalVia(p.Registry, outer, name, data, setup)
+}
+
+func (p Profiled) has(name string) bool {
+	return knows(p.Registry, name)
+}
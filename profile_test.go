@@ -0,0 +1,28 @@
+package typeregistry
+
+import "testing"
+
+func TestProfiled(t *testing.T) {
+	tests := []struct {
+		active string
+		opts   []ProfileOption
+		want   string
+	}{
+		{active: "production", opts: nil, want: "*typeregistry.nameType"},
+		{active: "staging", opts: []ProfileOption{OnlyIn("staging", "test")}, want: "*typeregistry.nameType"},
+		{active: "production", opts: []ProfileOption{OnlyIn("staging", "test")}, want: ""},
+		{active: "", opts: []ProfileOption{OnlyIn("staging")}, want: ""},
+	}
+	for i, test := range tests {
+		r := New()
+		p := Profiled{Registry: r, Active: test.active}
+		if got := p.AddIn(&nameType{}, test.opts...); got != test.want {
+			t.Errorf("%d AddIn() got %q, want %q", i, got, test.want)
+		}
+		if _, ok := r["*typeregistry.nameType"]; ok != (test.want != "") {
+			t.Errorf("%d AddIn() registered got %t, want %t", i, ok, test.want != "")
+		}
+	}
+}
+
+var _ Registry = Profiled{}
@@ -0,0 +1,53 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Projector runs projections, functions that transform unmarshaled values into
+// read models, for the types of a registry.
+type Projector struct {
+	Registry    Registry
+	projections map[reflect.Type][]reflect.Value
+}
+
+// NewProjector initializes a Projector with no projections.
+func NewProjector(r Registry) *Projector {
+	return &Projector{
+		Registry:    r,
+		projections: make(map[reflect.Type][]reflect.Value),
+	}
+}
+
+// Project adds o to the registry and registers fn to run on values of its
+// type. The function must take a single argument of o's type and return a
+// single value, the projection. It panics if fn is not such a function. It
+// returns the name that o was registered as.
+func (p *Projector) Project(o interface{}, fn interface{}) string {
+	name := p.Registry.Add(o)
+	t := reflect.TypeOf(o)
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 1 || !t.AssignableTo(f.Type().In(0)) {
+		panic(fmt.Sprintf("typeregistry projection for %s must be a func(%s) returning one value, got %T", name, t, fn))
+	}
+	p.projections[t] = append(p.projections[t], f)
+	return name
+}
+
+// RunProjections unmarshals data by name, as Unmarshal, and returns the
+// result of each projection registered for its type, in the order they were
+// registered.
+func (p *Projector) RunProjections(name string, data []byte, setup SetupFunc) ([]interface{}, error) {
+	o, err := p.Registry.Unmarshal(name, data, setup)
+	if err != nil {
+		return nil, err
+	}
+	fns := p.projections[reflect.TypeOf(o)]
+	results := make([]interface{}, 0, len(fns))
+	for _, f := range fns {
+		out := f.Call([]reflect.Value{reflect.ValueOf(o)})
+		results = append(results, out[0].Interface())
+	}
+	return results, nil
+}
@@ -0,0 +1,50 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProjector(t *testing.T) {
+	p := NewProjector(New())
+	name := p.Project(&unmarshalType{}, func(u *unmarshalType) string {
+		return "row:" + u.Name
+	})
+	p.Project(&unmarshalType{}, func(u interface{}) int {
+		return 1
+	})
+	other := p.Registry.Add(&nameType{})
+
+	got, err := p.RunProjections(name, []byte("ok"), NoSetup)
+	if err != nil {
+		t.Fatalf("RunProjections() wants no error, got: %s", err)
+	}
+	if want := []interface{}{"row:bin:ok", 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RunProjections() got %#v, want %#v", got, want)
+	}
+	got, err = p.RunProjections(other, nil, NoSetup)
+	if err != nil || len(got) != 0 {
+		t.Errorf("RunProjections(%s) got %#v, %v, want no projections", other, got, err)
+	}
+	p.Project(&unmarshalFailType{}, func(u *unmarshalFailType) bool { return true })
+	if _, err := p.RunProjections("*typeregistry.unmarshalFailType", nil, NoSetup); err == nil {
+		t.Errorf("RunProjections() of a failing type wants error, got none")
+	}
+
+	bad := []interface{}{
+		nil,
+		func() string { return "" },
+		func(u *nameType) string { return "" },
+		func(u *unmarshalType) {},
+	}
+	for i, fn := range bad {
+		var paniced bool
+		func() {
+			defer func() { paniced = recover() != nil }()
+			p.Project(&unmarshalType{}, fn)
+		}()
+		if !paniced {
+			t.Errorf("%d Project(%T) wants panic, got none", i, fn)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package typeregistry
+
+// ReadOnlyRegistry is a view of a TypeRegistry that can instantiate and
+// unmarshal types but not add them, to hand to code that should not change
+// what is registered, such as third party plugins.
+type ReadOnlyRegistry struct {
+	r TypeRegistry
+}
+
+// ReadOnly returns a view of the registry that cannot add types. Types added
+// to the registry later are seen by the view.
+func (r TypeRegistry) ReadOnly() ReadOnlyRegistry {
+	return ReadOnlyRegistry{r: r}
+}
+
+// New instantiates a type by name, as TypeRegistry.New.
+func (v ReadOnlyRegistry) New(name string) interface{} {
+	return v.r.New(name)
+}
+
+// Unmarshal decodes a type by name, as TypeRegistry.Unmarshal.
+func (v ReadOnlyRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return v.r.Unmarshal(name, data, setup)
+}
+
+// Names returns every registered name, in sorted order.
+func (v ReadOnlyRegistry) Names() []string {
+	return v.r.Names()
+}
+
+// Producer is the marshaling half of a Registry, for components that only
+// encode values.
+type Producer interface {
+	Marshal(o interface{}) (string, []byte, error)
+}
+
+// Consumer is the unmarshaling half of a Registry, for components that only
+// decode values.
+type Consumer interface {
+	New(name string) interface{}
+	Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error)
+}
+
+// MarshalOnly returns a view of r that can only marshal. The view cannot be
+// converted back to r, so a component given it cannot unmarshal or add types.
+func MarshalOnly(r Registry) Producer {
+	return producer{r: r}
+}
+
+// UnmarshalOnly returns a view of r that can only instantiate and unmarshal.
+// The view cannot be converted back to r, so a component given it cannot
+// marshal or add types.
+func UnmarshalOnly(r Registry) Consumer {
+	return consumer{r: r}
+}
+
+type producer struct {
+	r Registry
+}
+
+func (p producer) Marshal(o interface{}) (string, []byte, error) {
+	return p.r.Marshal(o)
+}
+
+type consumer struct {
+	r Registry
+}
+
+func (c consumer) New(name string) interface{} {
+	return c.r.New(name)
+}
+
+func (c consumer) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return c.r.Unmarshal(name, data, setup)
+}
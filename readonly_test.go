@@ -0,0 +1,61 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_ReadOnly(t *testing.T) {
+	r := New()
+	name := r.Add(&jsonType{})
+	v := r.ReadOnly()
+	r.Add(&nameType{})
+
+	want := []string{"*typeregistry.jsonType", "*typeregistry.nameType"}
+	if got := v.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() got %v, want %v", got, want)
+	}
+	if got := v.New(name); !reflect.DeepEqual(got, &jsonType{}) {
+		t.Errorf("New() got %#v, want %#v", got, &jsonType{})
+	}
+	got, err := v.Unmarshal(name, []byte(`{"ID":"x"}`), NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&jsonType{ID: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	if _, ok := interface{}(v).(interface{ Add(interface{}) string }); ok {
+		t.Errorf("ReadOnlyRegistry wants no Add method")
+	}
+}
+
+func TestMarshalOnly(t *testing.T) {
+	r := New()
+	r.Add(&jsonType{})
+	p := MarshalOnly(r)
+	name, data, err := p.Marshal(&jsonType{ID: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+
+	c := UnmarshalOnly(r)
+	got, err := c.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&jsonType{ID: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	if got := c.New(name); !reflect.DeepEqual(got, &jsonType{}) {
+		t.Errorf("New() got %#v, want %#v", got, &jsonType{})
+	}
+
+	if _, ok := p.(Consumer); ok {
+		t.Errorf("MarshalOnly() wants no Consumer")
+	}
+	if _, ok := c.(Producer); ok {
+		t.Errorf("UnmarshalOnly() wants no Producer")
+	}
+	var _ Consumer = r.ReadOnly()
+}
@@ -0,0 +1,151 @@
+package typeregistry
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Registrar collects types that packages register from their init functions,
+// in the same way as database/sql drivers. A domain package registers its
+// types:
+//
+//	func init() {
+//		typeregistry.DefaultRegistrar().MustRegister(&Invoice{})
+//	}
+//
+// and an application opts in to them with a blank import of the package.
+type Registrar struct {
+	mu       sync.Mutex
+	registry TypeRegistry
+	sites    map[string]callSite
+}
+
+// callSite is where a type was registered.
+type callSite struct {
+	pkg  string
+	file string
+	line int
+}
+
+func (c callSite) String() string {
+	return fmt.Sprintf("%s (%s:%d)", c.pkg, c.file, c.line)
+}
+
+var defaultRegistrar = NewRegistrar()
+
+// DefaultRegistrar returns the Registrar shared by the whole program.
+func DefaultRegistrar() *Registrar {
+	return defaultRegistrar
+}
+
+// NewRegistrar initializes an empty Registrar.
+func NewRegistrar() *Registrar {
+	return &Registrar{
+		registry: New(),
+		sites:    make(map[string]callSite),
+	}
+}
+
+// MustRegister adds o to the registry and returns its name. It panics if the
+// name is already registered, naming both registering packages and the files
+// and lines they registered it from.
+func (g *Registrar) MustRegister(o interface{}) string {
+	if o == nil {
+		panic("typeregistry cannot add nil")
+	}
+	site := caller(2)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	name := g.registry.name(o)
+	if other, ok := g.sites[name]; ok {
+		panic(fmt.Sprintf("typeregistry %#v registered by both %s and %s", name, other, site))
+	}
+	g.sites[name] = site
+	return g.registry.Add(o)
+}
+
+// Registry returns a copy of the registered types. Take it once registration
+// is complete, such as at the start of main.
+func (g *Registrar) Registry() TypeRegistry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r := make(TypeRegistry, len(g.registry))
+	for name, t := range g.registry {
+		r[name] = t
+	}
+	return r
+}
+
+// Dump writes each registered name, its type, and where it was registered to
+// w, one per line in order of name.
+func (g *Registrar) Dump(w io.Writer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, name := range g.registry.Names() {
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", name, typeID(g.registry[name]), g.sites[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// caller returns the call site skip frames up the stack.
+func caller(skip int) callSite {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return callSite{pkg: "unknown", file: "unknown"}
+	}
+	return callSite{pkg: funcPackage(runtime.FuncForPC(pc).Name()), file: file, line: line}
+}
+
+// ownPackage is the import path of this package.
+var ownPackage = reflect.TypeOf(callSite{}).PkgPath()
+
+// outsideCaller returns the nearest call site that is not in this package,
+// not counting its tests, so that a type added through a wrapper is recorded
+// as added by the code that called the wrapper.
+func outsideCaller() callSite {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if pkg := funcPackage(f.Function); pkg != ownPackage || strings.HasSuffix(f.File, "_test.go") {
+			return callSite{pkg: pkg, file: f.File, line: f.Line}
+		}
+		if !more {
+			return callSite{pkg: "unknown", file: "unknown"}
+		}
+	}
+}
+
+// funcPackage returns the import path of the package of a function named as
+// by runtime.Func.Name.
+func funcPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		name = name[:slash+1+dot]
+	}
+	return name
+}
+
+// siteCache holds the call site that first added each type.
+var siteCache sync.Map
+
+// recordSite records the call site adding t, if it is the first.
+func recordSite(t reflect.Type) {
+	if _, ok := siteCache.Load(t); !ok {
+		siteCache.LoadOrStore(t, outsideCaller())
+	}
+}
+
+// siteOf returns the call site that first added t.
+func siteOf(t reflect.Type) callSite {
+	if site, ok := siteCache.Load(t); ok {
+		return site.(callSite)
+	}
+	return callSite{pkg: "unknown", file: "unknown"}
+}
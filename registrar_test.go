@@ -0,0 +1,130 @@
+package typeregistry
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRegistrar(t *testing.T) {
+	if DefaultRegistrar() != DefaultRegistrar() {
+		t.Errorf("DefaultRegistrar() is not shared")
+	}
+
+	g := NewRegistrar()
+	_, file, line, _ := runtime.Caller(0)
+	name := g.MustRegister(&nameType{})
+	if name != "*typeregistry.nameType" {
+		t.Errorf("MustRegister() got %s, want %s", name, "*typeregistry.nameType")
+	}
+	r := g.Registry()
+	if _, ok := r[name]; !ok || len(r) != 1 {
+		t.Errorf("Registry() got %#v, want %s", r, name)
+	}
+	r.Add(nothingType{})
+	if len(g.Registry()) != 1 {
+		t.Errorf("Registry() is not a copy")
+	}
+
+	site := fmt.Sprintf("github.com/rcarver/typeregistry (%s:%d)", file, line+1)
+	var dump bytes.Buffer
+	if err := g.Dump(&dump); err != nil {
+		t.Errorf("Dump() wants no error, got: %s", err)
+	}
+	if want := "*typeregistry.nameType github.com/rcarver/typeregistry *typeregistry.nameType " + site + "\n"; dump.String() != want {
+		t.Errorf("Dump() got %q, want %q", dump.String(), want)
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		g.MustRegister(&nameType{})
+	}()
+	_, _, line, _ = runtime.Caller(0)
+	again := fmt.Sprintf("github.com/rcarver/typeregistry (%s:%d)", file, line-2)
+	if want := "typeregistry \"*typeregistry.nameType\" registered by both " + site + " and " + again; paniced != want {
+		t.Errorf("MustRegister() twice got panic %q, want %q", paniced, want)
+	}
+
+	paniced = ""
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		g.MustRegister(nil)
+	}()
+	if paniced != "typeregistry cannot add nil" {
+		t.Errorf("Expected MustRegister(nil) to panic, got %s", paniced)
+	}
+}
+
+func TestRegistrar_Dump(t *testing.T) {
+	g := NewRegistrar()
+	g.MustRegister(nothingType{})
+	g.MustRegister(&nameType{})
+	g.MustRegister(0)
+	g.MustRegister(marshalType{})
+
+	var dump bytes.Buffer
+	if err := g.Dump(&dump); err != nil {
+		t.Fatalf("Dump() wants no error, got: %s", err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(dump.String()), "\n") {
+		names = append(names, strings.Fields(line)[0])
+	}
+	want := []string{"*typeregistry.nameType", "int", "typeregistry.marshalType", "typeregistry.nothingType"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Dump() got names %v, want %v", names, want)
+	}
+}
+
+type conflictType struct{}
+
+func (conflictType) TypeName() string { return "conflict" }
+
+type otherConflictType struct{}
+
+func (otherConflictType) TypeName() string { return "conflict" }
+
+func TestTypeRegistry_Add_conflict(t *testing.T) {
+	r := New()
+	_, file, line, _ := runtime.Caller(0)
+	r.Add(conflictType{})
+	r.Add(conflictType{})
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		r.Add(otherConflictType{})
+	}()
+	want := fmt.Sprintf("typeregistry cannot add typeregistry.otherConflictType as \"conflict\", it is already typeregistry.conflictType "+
+		"(adding from github.com/rcarver/typeregistry (%s:%d), typeregistry.conflictType first added from github.com/rcarver/typeregistry (%s:%d))",
+		file, line+11, file, line+1)
+	if paniced != want {
+		t.Errorf("Add() of a different type got panic %q, want %q", paniced, want)
+	}
+	if r["conflict"] != reflect.TypeOf(conflictType{}) {
+		t.Errorf("Add() of a different type replaced %s", r["conflict"])
+	}
+
+	s := Safe(New())
+	s.Add(conflictType{})
+	err := s.Load().AddNamed("conflict", otherConflictType{})
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("first added from github.com/rcarver/typeregistry (%s:%d)", file, line+1)) {
+		t.Errorf("AddNamed() got error %v, want the first call site", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Package registrytest checks that an implementation of typeregistry.Registry
+// keeps the documented semantics of TypeRegistry, so that wrappers and custom
+// implementations cannot silently diverge from it.
+package registrytest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+type plain struct {
+	Name string
+}
+
+type document struct {
+	typeregistry.JSON
+	Title string
+	setup string
+}
+
+type custom struct {
+	Value string
+}
+
+func (c *custom) Marshal() ([]byte, error) {
+	return []byte(c.Value), nil
+}
+
+func (c *custom) Unmarshal(data []byte) error {
+	c.Value = string(data)
+	return nil
+}
+
+// named and renamed are different types that choose the same name.
+type named struct{}
+
+func (named) TypeName() string { return "registrytest.named" }
+
+type renamed struct{}
+
+func (renamed) TypeName() string { return "registrytest.named" }
+
+// aliaser is implemented by registries that know types by old names, such as
+// typeregistry.AliasRegistry.
+type aliaser interface {
+	Alias(old, canonical string) error
+}
+
+// Run checks the Registry returned by newRegistry, which must be empty, with
+// a subtest for each rule: instantiating added types, panics for nil and
+// unknown names, marshal and unmarshal round trips, setup running before
+// decoding, nil and empty data, adding a type twice, and adding a different
+// type under an existing name. If the Registry has an Alias method, like
+// typeregistry.AliasRegistry, it also checks that aliases resolve.
+func Run(t *testing.T, newRegistry func() typeregistry.Registry) {
+	t.Run("New", func(t *testing.T) {
+		r := newRegistry()
+		for _, o := range []interface{}{plain{}, &plain{}, &document{}} {
+			name := r.Add(o)
+			got := r.New(name)
+			if reflect.TypeOf(got) != reflect.TypeOf(o) {
+				t.Errorf("New(%q) got %T, want %T", name, got, o)
+			}
+			if v := reflect.ValueOf(got); v.Kind() == reflect.Ptr && v.IsNil() {
+				t.Errorf("New(%q) got a nil pointer", name)
+			}
+		}
+	})
+	t.Run("AddNil", func(t *testing.T) {
+		r := newRegistry()
+		if p := panics(func() { r.Add(nil) }); p == "" {
+			t.Errorf("Add(nil) wants panic, got none")
+		}
+	})
+	t.Run("Unknown", func(t *testing.T) {
+		r := newRegistry()
+		for _, f := range []func(){
+			func() { r.New("registrytest.unknown") },
+			func() { r.Unmarshal("registrytest.unknown", nil, typeregistry.NoSetup) },
+		} {
+			if p := panics(f); !strings.Contains(p, "typeregistry does not know") {
+				t.Errorf("unknown name wants panic that it is not known, got %q", p)
+			}
+		}
+	})
+	t.Run("AddTwice", func(t *testing.T) {
+		r := newRegistry()
+		a := r.Add(&plain{})
+		b := r.Add(&plain{})
+		if a != b {
+			t.Errorf("Add() twice got names %q and %q, want the same", a, b)
+		}
+	})
+	t.Run("AddConflict", func(t *testing.T) {
+		r := newRegistry()
+		name := r.Add(named{})
+		if p := panics(func() { r.Add(renamed{}) }); !strings.Contains(p, "typeregistry cannot add") {
+			t.Errorf("Add() of a different type as %q wants panic, got %q", name, p)
+		}
+		if got := r.New(name); reflect.TypeOf(got) != reflect.TypeOf(named{}) {
+			t.Errorf("New(%q) after a conflicting Add() got %T, want %T", name, got, named{})
+		}
+	})
+	t.Run("Alias", func(t *testing.T) {
+		r := newRegistry()
+		a, ok := r.(aliaser)
+		if !ok {
+			t.Skipf("%T has no Alias method", r)
+		}
+		name := r.Add(&custom{})
+		if err := a.Alias("registrytest.old", name); err != nil {
+			t.Fatalf("Alias() wants no error, got: %s", err)
+		}
+		if got := r.New("registrytest.old"); reflect.TypeOf(got) != reflect.TypeOf(&custom{}) {
+			t.Errorf("New() of an alias got %T, want %T", got, &custom{})
+		}
+		got, err := r.Unmarshal("registrytest.old", []byte("v"), typeregistry.NoSetup)
+		if err != nil {
+			t.Errorf("Unmarshal() of an alias wants no error, got: %s", err)
+		}
+		if want := (&custom{Value: "v"}); !reflect.DeepEqual(got, want) {
+			t.Errorf("Unmarshal() of an alias got %#v, want %#v", got, want)
+		}
+		if n, _, _ := r.Marshal(&custom{}); n != name {
+			t.Errorf("Marshal() got name %q, want %q", n, name)
+		}
+		if err := a.Alias("registrytest.missing", "registrytest.unknown"); err == nil {
+			t.Errorf("Alias() to an unknown name wants error, got none")
+		}
+	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		r := newRegistry()
+		r.Add(&document{})
+		r.Add(&custom{})
+		for _, o := range []interface{}{&document{Title: "t"}, &custom{Value: "v"}} {
+			name, data, err := r.Marshal(o)
+			if err != nil {
+				t.Errorf("Marshal(%T) wants no error, got: %s", o, err)
+				continue
+			}
+			got, err := r.Unmarshal(name, data, typeregistry.NoSetup)
+			if err != nil {
+				t.Errorf("Unmarshal(%q) wants no error, got: %s", name, err)
+			}
+			if !reflect.DeepEqual(got, o) {
+				t.Errorf("Unmarshal(%q) got %#v, want %#v", name, got, o)
+			}
+		}
+	})
+	t.Run("NoEncoding", func(t *testing.T) {
+		r := newRegistry()
+		r.Add(&plain{})
+		_, data, err := r.Marshal(&plain{Name: "x"})
+		if err != nil || data != nil {
+			t.Errorf("Marshal() of a type without an encoding got %q, %v, want nil data", data, err)
+		}
+	})
+	t.Run("Setup", func(t *testing.T) {
+		r := newRegistry()
+		r.Add(&document{})
+		name, data, err := r.Marshal(&document{Title: "t"})
+		if err != nil {
+			t.Fatalf("Marshal() wants no error, got: %s", err)
+		}
+		got, err := r.Unmarshal(name, data, func(o interface{}) {
+			d := o.(*document)
+			d.setup = fmt.Sprintf("title %q", d.Title)
+		})
+		if err != nil {
+			t.Fatalf("Unmarshal() wants no error, got: %s", err)
+		}
+		if d := got.(*document); d.setup != `title ""` || d.Title != "t" {
+			t.Errorf("Unmarshal() wants setup before decoding, got %#v", d)
+		}
+	})
+	t.Run("EmptyData", func(t *testing.T) {
+		r := newRegistry()
+		name := r.Add(&custom{})
+		a, errA := r.Unmarshal(name, nil, nil)
+		b, errB := r.Unmarshal(name, []byte{}, nil)
+		if errA != nil || errB != nil || !reflect.DeepEqual(a, b) {
+			t.Errorf("Unmarshal() of nil and empty data got %#v, %v and %#v, %v, want the same", a, errA, b, errB)
+		}
+	})
+}
+
+// panics returns the value f panics with, formatted, or "" if it does not.
+func panics(f func()) (p string) {
+	defer func() {
+		if r := recover(); r != nil {
+			p = fmt.Sprint(r)
+		}
+	}()
+	f()
+	return ""
+}
@@ -0,0 +1,27 @@
+package registrytest
+
+import (
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func() typeregistry.Registry
+	}{
+		{name: "TypeRegistry", new: func() typeregistry.Registry { return typeregistry.New() }},
+		{name: "Safe", new: func() typeregistry.Registry { return typeregistry.Safe(typeregistry.New()) }},
+		{name: "WithEncodings", new: func() typeregistry.Registry { return typeregistry.WithEncodings(typeregistry.New()) }},
+		{name: "WithResolvers", new: func() typeregistry.Registry { return typeregistry.WithResolvers(typeregistry.New()) }},
+		{name: "Namespace", new: func() typeregistry.Registry { return typeregistry.New().Namespace("ns") }},
+		{name: "WithAliases", new: func() typeregistry.Registry { return typeregistry.WithAliases(typeregistry.New()) }},
+		{name: "WithFieldCipher", new: func() typeregistry.Registry { return typeregistry.WithFieldCipher(typeregistry.New(), nil) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Run(t, test.new)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package typeregistry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ReplayStats counts the outcomes of unmarshaling the data of one type.
+type ReplayStats struct {
+	Succeeded int
+	Failed    int
+	// LastError is the message of the most recent failure.
+	LastError string
+}
+
+// ReplayReport holds the ReplayStats of each registered name seen by Replay.
+type ReplayReport map[string]*ReplayStats
+
+// Failed returns the number of items that failed to unmarshal.
+func (rr ReplayReport) Failed() int {
+	n := 0
+	for _, s := range rr {
+		n += s.Failed
+	}
+	return n
+}
+
+// String summarizes the report, one name per line in sorted order.
+func (rr ReplayReport) String() string {
+	names := make([]string, 0, len(rr))
+	for name := range rr {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		s := rr[name]
+		fmt.Fprintf(&b, "%s ok:%d failed:%d", name, s.Succeeded, s.Failed)
+		if s.LastError != "" {
+			fmt.Fprintf(&b, " last error: %s", s.LastError)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Replay reads a stream of one or more containers written by MarshalBatch,
+// such as a corpus of production data, and unmarshals every item with the
+// current registry. As in UnmarshalBatch, failures and unknown names do not
+// stop it, but instead of returning each failure it counts them by name in a
+// ReplayReport. Use it to check that changes to your types can still read
+// existing data. It only returns an error if the stream itself cannot be
+// read.
+func (r TypeRegistry) Replay(rd io.Reader, setup SetupFunc) (ReplayReport, error) {
+	report := make(ReplayReport)
+	br := bufio.NewReader(rd)
+	for {
+		count, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+		for i := uint64(0); i < count; i++ {
+			name, err := readStreamBytes(br)
+			if err != nil {
+				return report, err
+			}
+			data, err := readStreamBytes(br)
+			if err != nil {
+				return report, err
+			}
+			r.replay(report, string(name), data, setup)
+		}
+	}
+}
+
+// ReplayEnvelopes is Replay for a stream of envelopes written one after
+// another, such as by AppendEnvelope or EncodeVersionedEnvelope, instead of
+// containers written by MarshalBatch.
+func (r TypeRegistry) ReplayEnvelopes(rd io.Reader, setup SetupFunc) (ReplayReport, error) {
+	report := make(ReplayReport)
+	br := bufio.NewReader(rd)
+	for {
+		version, err := br.ReadByte()
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+		switch version {
+		case envelopeVersion:
+		case envelopeVersioned:
+			if _, err := readStreamBytes(br); err != nil {
+				return report, err
+			}
+		default:
+			return report, fmt.Errorf("typeregistry envelope has unknown version %d", version)
+		}
+		name, err := readStreamBytes(br)
+		if err != nil {
+			return report, err
+		}
+		data, err := readStreamBytes(br)
+		if err != nil {
+			return report, err
+		}
+		r.replay(report, string(name), data, setup)
+	}
+}
+
+func (r TypeRegistry) replay(report ReplayReport, name string, data []byte, setup SetupFunc) {
+	s, ok := report[name]
+	if !ok {
+		s = &ReplayStats{}
+		report[name] = s
+	}
+	if _, ok := r[name]; !ok {
+		s.Failed++
+		s.LastError = fmt.Sprintf("typeregistry does not know %#v", name)
+		return
+	}
+	if _, err := r.Unmarshal(name, data, setup); err != nil {
+		s.Failed++
+		s.LastError = err.Error()
+		return
+	}
+	s.Succeeded++
+}
+
+// readStreamBytes reads a length prefixed value. The length is not trusted to
+// allocate, so a corrupt length fails when the stream ends, not before.
+func readStreamBytes(br *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, streamError(err)
+	}
+	if size > math.MaxInt64 {
+		return nil, errTruncated
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, br, int64(size)); err != nil {
+		return nil, streamError(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// streamError returns errTruncated if the stream ended early, or err.
+func streamError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errTruncated
+	}
+	return err
+}
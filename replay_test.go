@@ -0,0 +1,108 @@
+package typeregistry
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// errReader fails every read.
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) { return 0, errors.New("disk") }
+
+func TestTypeRegistry_Replay(t *testing.T) {
+	old := New()
+	old.Add(&unmarshalType{})
+	old.Add(&unmarshalFailType{})
+	old.Add(&nameType{})
+
+	var corpus bytes.Buffer
+	for _, items := range [][]interface{}{
+		{&unmarshalType{}, &unmarshalFailType{}},
+		{&unmarshalType{}, &nameType{}},
+	} {
+		data, err := old.MarshalBatch(items)
+		if err != nil {
+			t.Fatalf("MarshalBatch() wants no error, got: %s", err)
+		}
+		corpus.Write(data)
+	}
+	full := corpus.Bytes()
+
+	current := New()
+	current.Add(&unmarshalType{})
+	current.Add(&unmarshalFailType{})
+	report, err := current.Replay(bytes.NewReader(full), NoSetup)
+	if err != nil {
+		t.Fatalf("Replay() wants no error, got: %s", err)
+	}
+	want := ReplayReport{
+		"*typeregistry.unmarshalType":     {Succeeded: 2},
+		"*typeregistry.unmarshalFailType": {Failed: 1, LastError: "Failed"},
+		"*typeregistry.nameType":          {Failed: 1, LastError: "typeregistry does not know \"*typeregistry.nameType\""},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Replay() got %s, want %s", report, want)
+	}
+	if got := report.Failed(); got != 2 {
+		t.Errorf("Failed() got %d, want 2", got)
+	}
+
+	if _, err := current.Replay(bytes.NewReader(full[:len(full)-1]), NoSetup); err != errTruncated {
+		t.Errorf("Replay() of a truncated stream got %v, want %v", err, errTruncated)
+	}
+	huge := []byte("\x01\xff\xff\xff\xff\xff\xff\xff\xff\x7f")
+	if _, err := current.Replay(bytes.NewReader(huge), NoSetup); err != errTruncated {
+		t.Errorf("Replay() of a huge length got %v, want %v", err, errTruncated)
+	}
+	failing := io.MultiReader(bytes.NewReader(full[:3]), errReader{})
+	if _, err := current.Replay(failing, NoSetup); err == nil || err.Error() != "disk" {
+		t.Errorf("Replay() of a failing reader got %v, want disk", err)
+	}
+}
+
+func TestTypeRegistry_ReplayEnvelopes(t *testing.T) {
+	old := New()
+	old.Add(&unmarshalType{})
+	old.Add(&unmarshalFailType{})
+	old.Add(&nameType{})
+
+	var corpus []byte
+	for _, o := range []interface{}{&unmarshalType{}, &unmarshalFailType{}, &unmarshalType{}, &nameType{}} {
+		var err error
+		if corpus, err = old.AppendEnvelope(corpus, o); err != nil {
+			t.Fatalf("AppendEnvelope() wants no error, got: %s", err)
+		}
+	}
+	versioned, err := Envelope{Name: "*typeregistry.unmarshalType", Version: "v1.0.0", Data: []byte("ok")}.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() wants no error, got: %s", err)
+	}
+	corpus = append(corpus, versioned...)
+
+	current := New()
+	current.Add(&unmarshalType{})
+	current.Add(&unmarshalFailType{})
+	report, err := current.ReplayEnvelopes(bytes.NewReader(corpus), NoSetup)
+	if err != nil {
+		t.Fatalf("ReplayEnvelopes() wants no error, got: %s", err)
+	}
+	want := ReplayReport{
+		"*typeregistry.unmarshalType":     {Succeeded: 3},
+		"*typeregistry.unmarshalFailType": {Failed: 1, LastError: "Failed"},
+		"*typeregistry.nameType":          {Failed: 1, LastError: "typeregistry does not know \"*typeregistry.nameType\""},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("ReplayEnvelopes() got %s, want %s", report, want)
+	}
+
+	if _, err := current.ReplayEnvelopes(bytes.NewReader(corpus[:len(corpus)-1]), NoSetup); err != errTruncated {
+		t.Errorf("ReplayEnvelopes() of a truncated stream got %v, want %v", err, errTruncated)
+	}
+	if _, err := current.ReplayEnvelopes(bytes.NewReader([]byte("\x03")), NoSetup); err == nil {
+		t.Errorf("ReplayEnvelopes() of an unknown version wants error, got none")
+	}
+}
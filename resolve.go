@@ -0,0 +1,79 @@
+package typeregistry
+
+import "sync"
+
+// ResolveFunc chooses the registered name to unmarshal data as, by looking at
+// the data itself.
+type ResolveFunc func(data []byte) string
+
+// ResolvingRegistry is a Registry in which some names are resolved to other
+// names by inspecting the data, such as a legacy name that was used for two
+// versions of a type that can be told apart by their fields.
+type ResolvingRegistry struct {
+	Registry Registry
+
+	mu        sync.RWMutex
+	resolvers map[string]ResolveFunc
+}
+
+// WithResolvers wraps a registry so that names can be resolved with Resolve.
+func WithResolvers(r Registry) *ResolvingRegistry {
+	return &ResolvingRegistry{Registry: r, resolvers: make(map[string]ResolveFunc)}
+}
+
+// Resolve makes Unmarshal call fn to choose the name to use for data stored
+// as name. The name need not be registered itself.
+func (r *ResolvingRegistry) Resolve(name string, fn ResolveFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolvers[name] = fn
+}
+
+// Add puts a new type in the registry.
+func (r *ResolvingRegistry) Add(o interface{}) string {
+	return r.Registry.Add(o)
+}
+
+// New instantiates a type by name. Names are not resolved, since there is
+// no data to inspect.
+func (r *ResolvingRegistry) New(name string) interface{} {
+	return r.Registry.New(name)
+}
+
+// Marshal encodes a type.
+func (r *ResolvingRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return r.marshalWith(r, o)
+}
+
+func (r *ResolvingRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(r.Registry, outer, o)
+}
+
+func (r *ResolvingRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(r.Registry, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name, first resolving the name if it has a
+// resolver. If the resolved name is unknown, it panics.
+func (r *ResolvingRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return r.unmarshalWith(r, name, data, setup)
+}
+
+func (r *ResolvingRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	r.mu.RLock()
+	fn, ok := r.resolvers[name]
+	r.mu.RUnlock()
+	if ok {
+		name = fn(data)
+	}
+	return unmarshalVia(r.Registry, outer, name, data, setup)
+}
+
+// has cannot know the name a resolver will choose, so it assumes that any
+// name with a resolver is known.
+func (r *ResolvingRegistry) has(name string) bool {
+	r.mu.RLock()
+	_, ok := r.resolvers[name]
+	r.mu.RUnlock()
+	return ok || knows(r.Registry, name)
+}
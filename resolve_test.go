@@ -0,0 +1,49 @@
+package typeregistry
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type orderV1 struct {
+	JSON
+	Total int
+}
+
+type orderV2 struct {
+	JSON
+	Total    int
+	Currency string
+}
+
+func TestWithResolvers(t *testing.T) {
+	r := WithResolvers(New())
+	v1 := r.Add(&orderV1{})
+	v2 := r.Add(&orderV2{})
+	r.Resolve("order", func(data []byte) string {
+		if bytes.Contains(data, []byte(`"Currency"`)) {
+			return v2
+		}
+		return v1
+	})
+
+	tests := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		{name: "order", data: `{"Total":5}`, want: &orderV1{Total: 5}},
+		{name: "order", data: `{"Total":5,"Currency":"EUR"}`, want: &orderV2{Total: 5, Currency: "EUR"}},
+		{name: v1, data: `{"Total":5,"Currency":"EUR"}`, want: &orderV1{Total: 5}},
+	}
+	for i, test := range tests {
+		got, err := r.Unmarshal(test.name, []byte(test.data), NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.want)
+		}
+	}
+}
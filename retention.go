@@ -0,0 +1,29 @@
+package typeregistry
+
+import "time"
+
+// Retention holds hints for how long stored values of a type should be kept,
+// for the storage layers built on the registry to apply. The zero value means
+// no hint. SaveConfig includes the hints of each type.
+type Retention struct {
+	// TTL is how long a value should be kept after it is stored. Zero means
+	// forever.
+	TTL time.Duration `json:"ttl,omitempty"`
+	// Class names a storage class for archived values, such as "cold".
+	Class string `json:"class,omitempty"`
+}
+
+// Retainer is implemented by types that carry retention hints. It is called on
+// the zero value of the type.
+type Retainer interface {
+	Retention() Retention
+}
+
+// Retention returns the retention hints of the type registered as name, or the
+// zero Retention if it is not a Retainer. If the name is unknown, it panics.
+func (r TypeRegistry) Retention(name string) Retention {
+	if o, ok := r.New(name).(Retainer); ok {
+		return o.Retention()
+	}
+	return Retention{}
+}
@@ -0,0 +1,54 @@
+package typeregistry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type retainType struct{}
+
+func (*retainType) Retention() Retention {
+	return Retention{TTL: 24 * time.Hour, Class: "cold"}
+}
+
+func TestTypeRegistry_Retention(t *testing.T) {
+	tests := []struct {
+		t    interface{}
+		want Retention
+	}{
+		{t: &retainType{}, want: Retention{TTL: 24 * time.Hour, Class: "cold"}},
+		{t: retainType{}, want: Retention{}},
+		{t: &nothingType{}, want: Retention{}},
+	}
+	for i, test := range tests {
+		r := New()
+		name := r.Add(test.t)
+		if got := r.Retention(name); got != test.want {
+			t.Errorf("%d Retention() got %#v, want %#v", i, got, test.want)
+		}
+	}
+}
+
+func TestTypeRegistry_SaveConfig_retention(t *testing.T) {
+	defer withBuildInfo(nil)()
+	r := New()
+	r.Add(&retainType{})
+	r.Add(retainType{})
+	data, err := r.SaveConfig()
+	if err != nil {
+		t.Fatalf("SaveConfig() wants no error, got: %s", err)
+	}
+	want := `[{"name":"*typeregistry.retainType","type":"github.com/rcarver/typeregistry *typeregistry.retainType","retention":{"ttl":86400000000000,"class":"cold"}},` +
+		`{"name":"typeregistry.retainType","type":"github.com/rcarver/typeregistry typeregistry.retainType"}]`
+	if string(data) != want {
+		t.Errorf("SaveConfig() got %s, want %s", data, want)
+	}
+	var entries []ConfigEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("json.Unmarshal() wants no error, got: %s", err)
+	}
+	if got := entries[0].Retention; got == nil || *got != r.Retention("*typeregistry.retainType") {
+		t.Errorf("Retention got %#v, want %#v", got, r.Retention("*typeregistry.retainType"))
+	}
+}
@@ -0,0 +1,39 @@
+package typeregistry
+
+import "errors"
+
+// Retryable marks err as transient, so that IsRetryable reports true for it
+// and for any error that wraps it. An Unmarshaler should mark errors this way
+// when unmarshaling fails for a reason that may go away, such as a dependency
+// injected by a SetupFunc being unavailable. It returns nil if err is nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err}
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string   { return e.err.Error() }
+func (e *retryableError) Unwrap() error   { return e.err }
+func (e *retryableError) Temporary() bool { return true }
+
+// IsRetryable reports whether err, or any error it wraps, is transient. An
+// error is transient if it was marked by Retryable or has a Temporary method
+// that returns true, as some net errors do. All other errors are permanent:
+// retrying with the same data, such as a corrupt payload, will fail again,
+// so the data should be set aside instead.
+func IsRetryable(err error) bool {
+	for err != nil {
+		if t, ok := err.(interface {
+			Temporary() bool
+		}); ok && t.Temporary() {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package typeregistry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type retryType struct {
+	available bool
+}
+
+func (m *retryType) Unmarshal(data []byte) error {
+	if !m.available {
+		return Retryable(errors.New("service unavailable"))
+	}
+	if len(data) == 0 {
+		return errors.New("corrupt")
+	}
+	return nil
+}
+
+type temporaryError bool
+
+func (e temporaryError) Error() string   { return "temporary" }
+func (e temporaryError) Temporary() bool { return bool(e) }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: errors.New("permanent"), want: false},
+		{err: Retryable(errors.New("transient")), want: true},
+		{err: fmt.Errorf("wrapped: %w", Retryable(errors.New("transient"))), want: true},
+		{err: temporaryError(true), want: true},
+		{err: temporaryError(false), want: false},
+		{err: Retryable(nil), want: false},
+	}
+	for i, test := range tests {
+		if got := IsRetryable(test.err); got != test.want {
+			t.Errorf("%d IsRetryable(%v) got %t, want %t", i, test.err, got, test.want)
+		}
+	}
+	if err := Retryable(errors.New("transient")); err.Error() != "transient" {
+		t.Errorf("Retryable() message got %q, want %q", err, "transient")
+	}
+
+	r := New()
+	name := r.Add(&retryType{})
+	unmarshals := []struct {
+		available bool
+		data      []byte
+		want      bool
+	}{
+		{available: false, data: []byte("ok"), want: true},
+		{available: true, data: nil, want: false},
+	}
+	for i, test := range unmarshals {
+		_, err := r.Unmarshal(name, test.data, func(o interface{}) {
+			o.(*retryType).available = test.available
+		})
+		if err == nil {
+			t.Fatalf("%d Unmarshal() wants error, got none", i)
+		}
+		if got := IsRetryable(err); got != test.want {
+			t.Errorf("%d IsRetryable(%v) got %t, want %t", i, err, got, test.want)
+		}
+	}
+}
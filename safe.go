@@ -0,0 +1,79 @@
+package typeregistry
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SafeRegistry is a Registry that can be used from many goroutines at once,
+// including while types are being added. Adding a type copies the registry,
+// so reads never wait, which suits registries that are read far more often
+// than they are added to.
+type SafeRegistry struct {
+	mu sync.Mutex
+	v  atomic.Value
+}
+
+// Safe returns a SafeRegistry holding a copy of r.
+func Safe(r TypeRegistry) *SafeRegistry {
+	s := &SafeRegistry{}
+	s.v.Store(r.clone())
+	return s
+}
+
+// Load returns the current registry, such as to call the TypeRegistry
+// methods that SafeRegistry does not have. It must not be modified.
+func (s *SafeRegistry) Load() TypeRegistry {
+	return s.v.Load().(TypeRegistry)
+}
+
+// Add puts a new type in the registry. If the type cannot be registered, it
+// panics.
+func (s *SafeRegistry) Add(o interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.Load().clone()
+	name := r.Add(o)
+	s.v.Store(r)
+	return name
+}
+
+// New instantiates a type by name. If the name is unknown, it panics.
+func (s *SafeRegistry) New(name string) interface{} {
+	return s.Load().New(name)
+}
+
+// Marshal encodes a type.
+func (s *SafeRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return s.marshalWith(s, o)
+}
+
+func (s *SafeRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(s.Load(), outer, o)
+}
+
+func (s *SafeRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(s.Load(), outer, dst, o)
+}
+
+// Unmarshal decodes a type by name. If the name is unknown, it panics.
+func (s *SafeRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return s.unmarshalWith(s, name, data, setup)
+}
+
+func (s *SafeRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return unmarshalVia(s.Load(), outer, name, data, setup)
+}
+
+func (s *SafeRegistry) has(name string) bool {
+	return s.Load().has(name)
+}
+
+// clone returns a copy of r.
+func (r TypeRegistry) clone() TypeRegistry {
+	c := make(TypeRegistry, len(r)+1)
+	for name, t := range r {
+		c[name] = t
+	}
+	return c
+}
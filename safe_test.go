@@ -0,0 +1,47 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSafe(t *testing.T) {
+	base := New()
+	base.Add(&unmarshalType{})
+	r := Safe(base)
+	base.Add(nothingType{})
+	if len(r.Load()) != 1 {
+		t.Errorf("Safe() did not copy the registry")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			r.Add(reflect.New(reflect.ArrayOf(i, reflect.TypeOf(0))).Elem().Interface())
+		}(i)
+		go func() {
+			defer wg.Done()
+			got, err := r.Unmarshal("*typeregistry.unmarshalType", []byte("ok"), NoSetup)
+			if err != nil {
+				t.Errorf("Unmarshal() wants no error, got: %s", err)
+			}
+			if want := (&unmarshalType{Name: "bin:ok"}); !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(r.Load()); got != 11 {
+		t.Errorf("Add() got %d types, want 11", got)
+	}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("[%d]int", i)
+		if _, _, err := r.Marshal(r.New(name)); err != nil {
+			t.Errorf("Marshal(New(%s)) wants no error, got: %s", name, err)
+		}
+	}
+}
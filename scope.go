@@ -0,0 +1,71 @@
+package typeregistry
+
+import "reflect"
+
+// Scope is a view of a TypeRegistry whose additions are discarded when it is
+// closed. It lets tests register fixture types into a shared registry without
+// leaking them into other tests.
+type Scope struct {
+	r     TypeRegistry
+	saved map[string]reflect.Type
+}
+
+// Scope returns a view of the registry whose additions are undone by Close.
+func (r TypeRegistry) Scope() *Scope {
+	return &Scope{r: r, saved: make(map[string]reflect.Type)}
+}
+
+// Add puts a new type in the registry until the scope is closed.
+func (s *Scope) Add(o interface{}) string {
+	if o != nil {
+		name := s.r.name(o)
+		if _, ok := s.saved[name]; !ok {
+			s.saved[name] = s.r[name]
+		}
+	}
+	return s.r.Add(o)
+}
+
+// New instantiates a type by name, as TypeRegistry.New.
+func (s *Scope) New(name string) interface{} {
+	return s.r.New(name)
+}
+
+// Marshal encodes a type, as TypeRegistry.Marshal.
+func (s *Scope) Marshal(o interface{}) (string, []byte, error) {
+	return s.marshalWith(s, o)
+}
+
+func (s *Scope) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(s.r, outer, o)
+}
+
+func (s *Scope) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return s.r.appendWith(outer, dst, o)
+}
+
+// Unmarshal decodes a type by name, as TypeRegistry.Unmarshal.
+func (s *Scope) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return s.unmarshalWith(s, name, data, setup)
+}
+
+func (s *Scope) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return unmarshalVia(s.r, outer, name, data, setup)
+}
+
+func (s *Scope) has(name string) bool {
+	return s.r.has(name)
+}
+
+// Close restores the registry to how it was before the scope's additions.
+func (s *Scope) Close() error {
+	for name, t := range s.saved {
+		if t == nil {
+			delete(s.r, name)
+		} else {
+			s.r[name] = t
+		}
+	}
+	s.saved = make(map[string]reflect.Type)
+	return nil
+}
@@ -0,0 +1,27 @@
+package typeregistry
+
+import "testing"
+
+func TestTypeRegistry_Scope(t *testing.T) {
+	r := New()
+	r.Add(nothingType{})
+
+	s := r.Scope()
+	name := s.Add(&nameType{})
+	s.Add(nothingType{})
+	if _, ok := r[name]; !ok {
+		t.Errorf("Scope Add(%s) is not in the registry", name)
+	}
+	if got := s.New(name); got == nil {
+		t.Errorf("Scope New(%s) got nil", name)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() wants no error, got: %s", err)
+	}
+	if _, ok := r[name]; ok {
+		t.Errorf("Close() left %s in the registry", name)
+	}
+	if _, ok := r["typeregistry.nothingType"]; !ok {
+		t.Errorf("Close() removed a type added outside of the scope")
+	}
+}
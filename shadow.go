@@ -0,0 +1,100 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Divergence describes data that a candidate registry unmarshaled differently
+// from the current registry.
+type Divergence struct {
+	Name         string
+	Data         []byte
+	Current      interface{}
+	CurrentErr   error
+	Candidate    interface{}
+	CandidateErr error
+}
+
+// ShadowRegistry is a Registry that also unmarshals everything with a
+// candidate registry and reports when the results differ, while always
+// returning the current registry's result. Use it to try out new versions of
+// types or encodings against live data without risk.
+type ShadowRegistry struct {
+	Registry  Registry
+	Candidate Registry
+	// OnDiverge, if set, is called each time the results differ.
+	OnDiverge func(Divergence)
+}
+
+// Shadow wraps current so that its unmarshaling is shadowed by candidate.
+// Types must be added to each registry separately; Add only adds to current.
+func Shadow(current, candidate Registry, onDiverge func(Divergence)) *ShadowRegistry {
+	return &ShadowRegistry{Registry: current, Candidate: candidate, OnDiverge: onDiverge}
+}
+
+// Add puts a new type in the current registry.
+func (s *ShadowRegistry) Add(o interface{}) string {
+	return s.Registry.Add(o)
+}
+
+// New instantiates a type by name from the current registry.
+func (s *ShadowRegistry) New(name string) interface{} {
+	return s.Registry.New(name)
+}
+
+// Marshal encodes a type with the current registry.
+func (s *ShadowRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return s.marshalWith(s, o)
+}
+
+func (s *ShadowRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	return marshalVia(s.Registry, outer, o)
+}
+
+func (s *ShadowRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendVia(s.Registry, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name with both registries and returns the
+// current registry's result. The setup function is called for each. A
+// candidate that panics, such as for an unknown name, counts as an error.
+func (s *ShadowRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return s.unmarshalWith(s, name, data, setup)
+}
+
+func (s *ShadowRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	o, err := unmarshalVia(s.Registry, outer, name, data, setup)
+	co, cerr := s.shadow(name, data, setup)
+	if s.OnDiverge != nil && (!reflect.DeepEqual(o, co) || errString(err) != errString(cerr)) {
+		s.OnDiverge(Divergence{
+			Name:         name,
+			Data:         data,
+			Current:      o,
+			CurrentErr:   err,
+			Candidate:    co,
+			CandidateErr: cerr,
+		})
+	}
+	return o, err
+}
+
+func (s *ShadowRegistry) has(name string) bool {
+	return knows(s.Registry, name)
+}
+
+func (s *ShadowRegistry) shadow(name string, data []byte, setup SetupFunc) (o interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			o, err = nil, fmt.Errorf("%v", p)
+		}
+	}()
+	return s.Candidate.Unmarshal(name, data, setup)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,80 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+// unmarshalV2Type decodes the same data differently than unmarshalType.
+type unmarshalV2Type struct {
+	Name string
+}
+
+func (m *unmarshalV2Type) Unmarshal(data []byte) error {
+	m.Name = "v2:" + string(data)
+	return nil
+}
+
+func TestShadow(t *testing.T) {
+	current := New()
+	candidate := New()
+	var got []Divergence
+	s := Shadow(current, candidate, func(d Divergence) {
+		got = append(got, d)
+	})
+	s.Add(&unmarshalType{})
+	s.Add(&nameType{})
+	s.Add(&unmarshalFailType{})
+	candidate.Add(&nameType{})
+	candidate["*typeregistry.unmarshalType"] = reflect.TypeOf(&unmarshalV2Type{})
+
+	tests := []struct {
+		name     string
+		want     interface{}
+		err      bool
+		diverged bool
+	}{
+		{
+			name:     "*typeregistry.nameType",
+			want:     &nameType{},
+			diverged: false,
+		},
+		{
+			name:     "*typeregistry.unmarshalType",
+			want:     &unmarshalType{Name: "bin:ok"},
+			diverged: true,
+		},
+		{
+			name:     "*typeregistry.unmarshalFailType",
+			want:     &unmarshalFailType{},
+			err:      true,
+			diverged: true,
+		},
+	}
+	for i, test := range tests {
+		got = nil
+		o, err := s.Unmarshal(test.name, []byte("ok"), NoSetup)
+		if (err != nil) != test.err {
+			t.Errorf("%d Unmarshal() error got %v, want error %t", i, err, test.err)
+		}
+		if !reflect.DeepEqual(o, test.want) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, o, test.want)
+		}
+		if diverged := len(got) == 1; diverged != test.diverged {
+			t.Errorf("%d Unmarshal() divergences got %#v, want diverged %t", i, got, test.diverged)
+		}
+	}
+}
+
+func TestShadow_noOnDiverge(t *testing.T) {
+	current := New()
+	s := Shadow(current, New(), nil)
+	s.Add(&unmarshalType{})
+	o, err := s.Unmarshal("*typeregistry.unmarshalType", []byte("ok"), NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:ok"}); !reflect.DeepEqual(o, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", o, want)
+	}
+}
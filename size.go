@@ -0,0 +1,30 @@
+package typeregistry
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// entryOverhead approximates the memory used by one map entry apart from its
+// key and value: the hash byte plus bucket and overflow slack.
+const entryOverhead = 8
+
+// SizeInfo describes the approximate memory used by a registry.
+type SizeInfo struct {
+	// Types is the number of registered names.
+	Types int
+	// Bytes is the approximate number of bytes held by the registry's
+	// entries. It does not include the type information itself, which is
+	// part of the binary.
+	Bytes int
+}
+
+// SizeInfo returns the approximate memory used by the registry.
+func (r TypeRegistry) SizeInfo() SizeInfo {
+	var t reflect.Type
+	info := SizeInfo{Types: len(r)}
+	for name := range r {
+		info.Bytes += len(name) + int(unsafe.Sizeof(name)) + int(unsafe.Sizeof(t)) + entryOverhead
+	}
+	return info
+}
@@ -0,0 +1,20 @@
+package typeregistry
+
+import "testing"
+
+func TestTypeRegistry_SizeInfo(t *testing.T) {
+	r := New()
+	if got := r.SizeInfo(); got != (SizeInfo{}) {
+		t.Errorf("SizeInfo() of empty registry got %#v, want zero", got)
+	}
+	r.Add(nothingType{})
+	one := r.SizeInfo()
+	if one.Types != 1 || one.Bytes <= len("typeregistry.nothingType") {
+		t.Errorf("SizeInfo() got %#v, want 1 type and more than the name's bytes", one)
+	}
+	r.Add(&nothingType{})
+	two := r.SizeInfo()
+	if two.Types != 2 || two.Bytes <= one.Bytes {
+		t.Errorf("SizeInfo() got %#v, want 2 types and more than %d bytes", two, one.Bytes)
+	}
+}
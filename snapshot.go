@@ -0,0 +1,114 @@
+package typeregistry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// SnapshotVersion is the version of the format written by Snapshot.
+const SnapshotVersion = 1
+
+var snapshotMagic = []byte("TRSNAP")
+
+// SnapshotProvider supplies the live objects to capture in a snapshot.
+type SnapshotProvider interface {
+	SnapshotObjects() []interface{}
+}
+
+// SnapshotProviderFunc adapts a function to a SnapshotProvider.
+type SnapshotProviderFunc func() []interface{}
+
+// SnapshotObjects calls f.
+func (f SnapshotProviderFunc) SnapshotObjects() []interface{} {
+	return f()
+}
+
+// Snapshot marshals every object supplied by p into a single versioned blob.
+// Use Restore to get the objects back.
+func (r TypeRegistry) Snapshot(p SnapshotProvider) ([]byte, error) {
+	return Snapshot(r, p)
+}
+
+// AppendSnapshot is Snapshot, but appends the blob to dst and returns the
+// extended buffer.
+func (r TypeRegistry) AppendSnapshot(dst []byte, p SnapshotProvider) ([]byte, error) {
+	return AppendSnapshot(r, dst, p)
+}
+
+// Snapshot is TypeRegistry.Snapshot for any Registry.
+func Snapshot(r Registry, p SnapshotProvider) ([]byte, error) {
+	return AppendSnapshot(r, nil, p)
+}
+
+// AppendSnapshot is TypeRegistry.AppendSnapshot for any Registry.
+func AppendSnapshot(r Registry, dst []byte, p SnapshotProvider) ([]byte, error) {
+	buf := append(dst, snapshotMagic...)
+	buf = appendUvarint(buf, SnapshotVersion)
+	return AppendBatch(r, buf, p.SnapshotObjects())
+}
+
+// WriteSnapshot writes a snapshot of the objects supplied by p to w.
+func (r TypeRegistry) WriteSnapshot(w io.Writer, p SnapshotProvider) error {
+	data, err := r.Snapshot(p)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// SnapshotEvery takes a snapshot of the objects supplied by p each time d
+// elapses and passes it to save. Call the returned function to stop.
+func (r TypeRegistry) SnapshotEvery(d time.Duration, p SnapshotProvider, save func([]byte, error)) (stop func()) {
+	ticker := time.NewTicker(d)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				save(r.Snapshot(p))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// Restore decodes a blob created by Snapshot. The setup function is called
+// for each object, as in Unmarshal.
+func (r TypeRegistry) Restore(data []byte, setup SetupFunc) ([]interface{}, error) {
+	return Restore(r, data, setup)
+}
+
+// Restore is TypeRegistry.Restore for any Registry.
+func Restore(r Registry, data []byte, setup SetupFunc) ([]interface{}, error) {
+	if !bytes.HasPrefix(data, snapshotMagic) {
+		return nil, fmt.Errorf("typeregistry data is not a snapshot")
+	}
+	data = data[len(snapshotMagic):]
+	version, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errTruncated
+	}
+	if version != SnapshotVersion {
+		return nil, fmt.Errorf("typeregistry snapshot version %d is not supported", version)
+	}
+	return UnmarshalBatch(r, data[n:], setup)
+}
+
+// ReadSnapshot reads a snapshot from rd and restores it.
+func (r TypeRegistry) ReadSnapshot(rd io.Reader, setup SetupFunc) ([]interface{}, error) {
+	data, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	return r.Restore(data, setup)
+}
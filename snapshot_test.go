@@ -0,0 +1,76 @@
+package typeregistry
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypeRegistry_Snapshot(t *testing.T) {
+	r := New()
+	r.Add(marshalType{})
+	r.Add(&nameType{})
+
+	live := SnapshotProviderFunc(func() []interface{} {
+		return []interface{}{marshalType{Name: "ok"}, &nameType{}}
+	})
+	var buf bytes.Buffer
+	if err := r.WriteSnapshot(&buf, live); err != nil {
+		t.Fatalf("WriteSnapshot() wants no error, got: %s", err)
+	}
+	appended, err := r.AppendSnapshot([]byte("head"), live)
+	if err != nil {
+		t.Fatalf("AppendSnapshot() wants no error, got: %s", err)
+	}
+	if want := "head" + buf.String(); string(appended) != want {
+		t.Errorf("AppendSnapshot() got %q, want %q", appended, want)
+	}
+	got, err := r.ReadSnapshot(&buf, func(i interface{}) {
+		if x, ok := i.(*nameType); ok {
+			x.Name = "setup"
+		}
+	})
+	if err != nil {
+		t.Fatalf("ReadSnapshot() wants no error, got: %s", err)
+	}
+	want := []interface{}{marshalType{}, &nameType{Name: "setup"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadSnapshot() got %#v, want %#v", got, want)
+	}
+
+	tests := []struct {
+		data []byte
+	}{
+		{data: nil},
+		{data: []byte("not a snapshot")},
+		{data: append([]byte("TRSNAP"), 2, 0)},
+	}
+	for i, test := range tests {
+		if _, err := r.Restore(test.data, NoSetup); err == nil {
+			t.Errorf("%d Restore(%q) wants error, got none", i, test.data)
+		}
+	}
+}
+
+func TestTypeRegistry_SnapshotEvery(t *testing.T) {
+	r := New()
+	r.Add(&nameType{})
+	saved := make(chan []byte, 1)
+	stop := r.SnapshotEvery(time.Millisecond, SnapshotProviderFunc(func() []interface{} {
+		return []interface{}{&nameType{}}
+	}), func(data []byte, err error) {
+		if err != nil {
+			t.Errorf("SnapshotEvery() wants no error, got: %s", err)
+		}
+		select {
+		case saved <- data:
+		default:
+		}
+	})
+	data := <-saved
+	stop()
+	if got, err := r.Restore(data, NoSetup); err != nil || len(got) != 1 {
+		t.Errorf("Restore() got %#v, %v, want one object", got, err)
+	}
+}
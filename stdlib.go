@@ -0,0 +1,140 @@
+//go:build !typeregistry_minimal
+// +build !typeregistry_minimal
+
+package typeregistry
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// stdCodec encodes a standard library type, which cannot implement Marshaler
+// and Unmarshaler itself.
+type stdCodec struct {
+	marshal   func(o interface{}) ([]byte, error)
+	unmarshal func(data []byte) (interface{}, error)
+}
+
+// stdCodecs holds the built-in encodings of standard library types. Each is
+// a readable text form that round trips exactly.
+var stdCodecs = map[reflect.Type]stdCodec{
+	reflect.TypeOf(time.Time{}): {
+		marshal: func(o interface{}) ([]byte, error) {
+			return o.(time.Time).MarshalText()
+		},
+		unmarshal: func(data []byte) (interface{}, error) {
+			var t time.Time
+			if len(data) == 0 {
+				return t, nil
+			}
+			err := t.UnmarshalText(data)
+			return t, err
+		},
+	},
+	reflect.TypeOf(time.Duration(0)): {
+		marshal: func(o interface{}) ([]byte, error) {
+			return []byte(o.(time.Duration).String()), nil
+		},
+		unmarshal: func(data []byte) (interface{}, error) {
+			if len(data) == 0 {
+				return time.Duration(0), nil
+			}
+			return time.ParseDuration(string(data))
+		},
+	},
+	reflect.TypeOf(net.IP{}): {
+		marshal: func(o interface{}) ([]byte, error) {
+			if ip := o.(net.IP); len(ip) != 0 {
+				return []byte(ip.String()), nil
+			}
+			return nil, nil
+		},
+		unmarshal: func(data []byte) (interface{}, error) {
+			if len(data) == 0 {
+				return net.IP(nil), nil
+			}
+			if ip := net.ParseIP(string(data)); ip != nil {
+				return ip, nil
+			}
+			return net.IP(nil), fmt.Errorf("typeregistry invalid IP address %q", data)
+		},
+	},
+	reflect.TypeOf(url.URL{}): {
+		marshal: func(o interface{}) ([]byte, error) {
+			u := o.(url.URL)
+			return []byte(u.String()), nil
+		},
+		unmarshal: func(data []byte) (interface{}, error) {
+			u, err := url.Parse(string(data))
+			if err != nil {
+				return url.URL{}, err
+			}
+			return *u, nil
+		},
+	},
+	reflect.TypeOf(&url.URL{}): {
+		marshal: func(o interface{}) ([]byte, error) {
+			return []byte(o.(*url.URL).String()), nil
+		},
+		unmarshal: func(data []byte) (interface{}, error) {
+			return url.Parse(string(data))
+		},
+	},
+	reflect.TypeOf([16]byte{}): {
+		marshal: func(o interface{}) ([]byte, error) {
+			b := o.([16]byte)
+			s := hex.EncodeToString(b[:])
+			return []byte(s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]), nil
+		},
+		unmarshal: func(data []byte) (interface{}, error) {
+			var b [16]byte
+			if len(data) == 0 {
+				return b, nil
+			}
+			s := strings.Replace(string(data), "-", "", -1)
+			if hex.DecodedLen(len(s)) != len(b) {
+				return b, fmt.Errorf("typeregistry invalid UUID %q", data)
+			}
+			_, err := hex.Decode(b[:], []byte(s))
+			return b, err
+		},
+	},
+}
+
+// AddStdlib puts the standard library types with built-in encodings in the
+// registry: time.Time, time.Duration, net.IP, url.URL, *url.URL, and [16]byte
+// (for UUIDs). They are registered under their usual names, such as
+// "time.Time" and "[16]uint8", and marshal to a readable text form.
+func (r TypeRegistry) AddStdlib() {
+	for t := range stdCodecs {
+		r[t.String()] = t
+	}
+}
+
+func hasStd(t reflect.Type) bool {
+	_, ok := stdCodecs[t]
+	return ok
+}
+
+func marshalStd(o interface{}) ([]byte, bool, error) {
+	c, ok := stdCodecs[reflect.TypeOf(o)]
+	if !ok {
+		return nil, false, nil
+	}
+	data, err := c.marshal(o)
+	return data, true, err
+}
+
+func unmarshalStd(instance interface{}, data []byte) (interface{}, bool, error) {
+	c, ok := stdCodecs[reflect.TypeOf(instance)]
+	if !ok {
+		return instance, false, nil
+	}
+	o, err := c.unmarshal(data)
+	return o, true, err
+}
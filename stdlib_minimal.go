@@ -0,0 +1,20 @@
+//go:build typeregistry_minimal
+// +build typeregistry_minimal
+
+package typeregistry
+
+import "reflect"
+
+// Minimal builds have no standard library encodings.
+
+func hasStd(t reflect.Type) bool {
+	return false
+}
+
+func marshalStd(o interface{}) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func unmarshalStd(instance interface{}, data []byte) (interface{}, bool, error) {
+	return instance, false, nil
+}
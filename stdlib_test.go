@@ -0,0 +1,79 @@
+//go:build !typeregistry_minimal
+// +build !typeregistry_minimal
+
+package typeregistry
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypeRegistry_AddStdlib(t *testing.T) {
+	u, _ := url.Parse("https://example.com/a?b=c")
+	tests := []struct {
+		o    interface{}
+		name string
+		data string
+	}{
+		{
+			o:    time.Date(2016, 1, 2, 3, 4, 5, 6, time.UTC),
+			name: "time.Time",
+			data: "2016-01-02T03:04:05.000000006Z",
+		},
+		{
+			o:    90 * time.Second,
+			name: "time.Duration",
+			data: "1m30s",
+		},
+		{
+			o:    net.ParseIP("10.0.0.1"),
+			name: "net.IP",
+			data: "10.0.0.1",
+		},
+		{
+			o:    *u,
+			name: "url.URL",
+			data: "https://example.com/a?b=c",
+		},
+		{
+			o:    u,
+			name: "*url.URL",
+			data: "https://example.com/a?b=c",
+		},
+		{
+			o:    [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 1, 2, 3, 4, 5, 6, 7, 8},
+			name: "[16]uint8",
+			data: "12345678-9abc-def0-0102-030405060708",
+		},
+	}
+	r := New()
+	r.AddStdlib()
+	for i, test := range tests {
+		name, data, err := r.Marshal(test.o)
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if name != test.name {
+			t.Errorf("%d Marshal() name got %s, want %s", i, name, test.name)
+		}
+		if string(data) != test.data {
+			t.Errorf("%d Marshal() data got %s, want %s", i, data, test.data)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, test.o) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, test.o)
+		}
+	}
+
+	for _, name := range []string{"time.Time", "time.Duration", "net.IP", "[16]uint8"} {
+		if _, err := r.Unmarshal(name, []byte("garbage"), NoSetup); err == nil {
+			t.Errorf("Unmarshal(%s) of bad data wants error, got none", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+package typeregistry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Bus delivers envelopes to the handlers subscribed to it, such as a message
+// queue client. An error returned by a handler is passed back to the bus, to
+// retry or dead letter the message as it sees fit.
+type Bus interface {
+	Subscribe(handler func(ctx context.Context, e Envelope) error)
+}
+
+// SetupProvider returns the SetupFunc to decode one value with, such as one
+// that sets collaborators carried by ctx. It may return nil.
+type SetupProvider func(ctx context.Context) SetupFunc
+
+// Subscribe calls handler with each envelope on bus whose name is registered
+// in r as a T, or as a type implementing T if it is an interface, decoded by
+// r. Other envelopes are ignored. If setup is not nil, the SetupFunc it
+// provides is called before each value is decoded, as in Unmarshal. Whether a
+// name is a T is found by instantiating it the first time it is seen.
+func Subscribe[T any](bus Bus, r Registry, setup SetupProvider, handler func(context.Context, T) error) {
+	var mu sync.Mutex
+	names := make(map[string]bool)
+	isT := func(name string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		is, ok := names[name]
+		if !ok && knows(r, name) {
+			_, is = r.New(name).(T)
+			names[name] = is
+		}
+		return is
+	}
+	bus.Subscribe(func(ctx context.Context, e Envelope) error {
+		if !isT(e.Name) {
+			return nil
+		}
+		var s SetupFunc
+		if setup != nil {
+			s = setup(ctx)
+		}
+		o, err := r.Unmarshal(e.Name, e.Data, s)
+		if err != nil {
+			return err
+		}
+		t, ok := o.(T)
+		if !ok {
+			return fmt.Errorf("typeregistry %#v is %T, not %s", e.Name, o, typeString[T]())
+		}
+		return handler(ctx, t)
+	})
+}
@@ -0,0 +1,109 @@
+//go:build go1.18
+// +build go1.18
+
+package typeregistry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// memoryBus is a Bus that delivers published envelopes to every handler.
+type memoryBus struct {
+	handlers []func(context.Context, Envelope) error
+}
+
+func (b *memoryBus) Subscribe(handler func(ctx context.Context, e Envelope) error) {
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *memoryBus) publish(r Registry, o interface{}) error {
+	name, data, err := r.Marshal(o)
+	if err != nil {
+		return err
+	}
+	return b.send(Envelope{Name: name, Data: data})
+}
+
+func (b *memoryBus) send(e Envelope) error {
+	for _, h := range b.handlers {
+		if err := h(context.Background(), e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type svcKey struct{}
+
+func TestSubscribe(t *testing.T) {
+	r := New()
+	r.Add(&jobType{})
+	if err := r.AddNamed("legacy-job", &jobType{}); err != nil {
+		t.Fatalf("AddNamed() wants no error, got: %s", err)
+	}
+	r.Add(&jsonType{})
+	r.Add(&unmarshalFailType{})
+	bus := &memoryBus{}
+
+	var got []*jobType
+	Subscribe(bus, r, func(ctx context.Context) SetupFunc {
+		return func(o interface{}) {
+			o.(*jobType).svc, _ = ctx.Value(svcKey{}).(string)
+		}
+	}, func(ctx context.Context, j *jobType) error {
+		got = append(got, j)
+		return nil
+	})
+	var jobs []job
+	Subscribe(bus, r, nil, func(ctx context.Context, j job) error {
+		jobs = append(jobs, j)
+		return nil
+	})
+	var fails int
+	Subscribe(bus, r, nil, func(ctx context.Context, u *unmarshalFailType) error {
+		fails++
+		return nil
+	})
+
+	for _, o := range []interface{}{&jobType{Name: "a"}, &jsonType{ID: "x"}} {
+		if err := bus.publish(r, o); err != nil {
+			t.Fatalf("publish() wants no error, got: %s", err)
+		}
+	}
+	for _, e := range []Envelope{
+		{Name: "legacy-job", Data: []byte(`{"Name":"b"}`)},
+		{Name: "unknown", Data: []byte("x")},
+	} {
+		if err := bus.send(e); err != nil {
+			t.Fatalf("send() wants no error, got: %s", err)
+		}
+	}
+	want := []*jobType{{Name: "a"}, {Name: "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscribe() got %#v, want %#v", got, want)
+	}
+	if len(jobs) != 2 {
+		t.Errorf("Subscribe() to an interface got %d jobs, want 2", len(jobs))
+	}
+
+	got = nil
+	ctx := context.WithValue(context.Background(), svcKey{}, "svc")
+	for _, h := range bus.handlers {
+		if err := h(ctx, Envelope{Name: "*typeregistry.jobType", Data: []byte(`{"Name":"c"}`)}); err != nil {
+			t.Fatalf("handler wants no error, got: %s", err)
+		}
+	}
+	if want := []*jobType{{Name: "c", svc: "svc"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscribe() with setup got %#v, want %#v", got, want)
+	}
+
+	err := bus.send(Envelope{Name: "*typeregistry.unmarshalFailType", Data: []byte("x")})
+	if err == nil || err.Error() != "Failed" {
+		t.Errorf("Subscribe() of a failing value got error %v, want Failed", err)
+	}
+	if fails != 0 {
+		t.Errorf("Subscribe() called the handler %d times for a failing value", fails)
+	}
+}
@@ -0,0 +1,18 @@
+package typeregistry
+
+import "fmt"
+
+// Subset returns a new, independent registry holding only the given names,
+// such as the public types to embed in a client SDK. If a name is unknown, it
+// panics.
+func (r TypeRegistry) Subset(names ...string) TypeRegistry {
+	s := make(TypeRegistry, len(names))
+	for _, name := range names {
+		t, ok := r[name]
+		if !ok {
+			panic(fmt.Sprintf("typeregistry does not know %#v", name))
+		}
+		s[name] = t
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package typeregistry
+
+import "testing"
+
+func TestTypeRegistry_Subset(t *testing.T) {
+	r := New()
+	public := r.Add(&nameType{})
+	internal := r.Add(&nothingType{})
+
+	s := r.Subset(public)
+	if len(s) != 1 {
+		t.Errorf("Subset() got %d types, want 1", len(s))
+	}
+	if _, ok := s[internal]; ok {
+		t.Errorf("Subset() includes %s", internal)
+	}
+	s.Add(marshalType{})
+	if len(r) != 2 {
+		t.Errorf("Subset() is not independent, registry has %d types", len(r))
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		r.Subset("foo")
+	}()
+	if paniced != "typeregistry does not know \"foo\"" {
+		t.Errorf("Expected Subset(\"foo\") to panic, got %s", paniced)
+	}
+}
@@ -0,0 +1,42 @@
+package typeregistry
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// FuncMap returns functions for use in text/template and html/template, so
+// that templates for dashboards or generated code can use the registry:
+//
+//	new "pkg.T"        instantiates a registered type
+//	marshal .          returns the marshaled data of a value as a string
+//	describe "pkg.T"   renders a new instance of a registered type with StyleTable
+//
+// The functions return an error, instead of panicking, for unknown names.
+func (r TypeRegistry) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"new": func(name string) (interface{}, error) {
+			if err := r.known(name); err != nil {
+				return nil, err
+			}
+			return r.New(name), nil
+		},
+		"marshal": func(o interface{}) (string, error) {
+			_, data, err := r.Marshal(o)
+			return string(data), err
+		},
+		"describe": func(name string) (string, error) {
+			if err := r.known(name); err != nil {
+				return "", err
+			}
+			return r.Format(r.New(name), StyleTable), nil
+		},
+	}
+}
+
+func (r TypeRegistry) known(name string) error {
+	if _, ok := r[name]; !ok {
+		return fmt.Errorf("typeregistry does not know %#v", name)
+	}
+	return nil
+}
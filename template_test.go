@@ -0,0 +1,58 @@
+package typeregistry
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestTypeRegistry_FuncMap(t *testing.T) {
+	r := New()
+	r.Add(marshalType{})
+
+	tests := []struct {
+		text string
+		data interface{}
+		want string
+		err  bool
+	}{
+		{
+			text: `{{printf "%T" (new "typeregistry.marshalType")}}`,
+			want: "typeregistry.marshalType",
+		},
+		{
+			text: `{{marshal .}}`,
+			data: marshalType{Name: "ok"},
+			want: "bin:ok",
+		},
+		{
+			text: `{{describe "typeregistry.marshalType"}}`,
+			want: "typeregistry.marshalType\nName  \nFail  false\n",
+		},
+		{
+			text: `{{new "nope"}}`,
+			err:  true,
+		},
+		{
+			text: `{{describe "nope"}}`,
+			err:  true,
+		},
+	}
+	for i, test := range tests {
+		tmpl := template.Must(template.New("").Funcs(r.FuncMap()).Parse(test.text))
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, test.data)
+		if test.err {
+			if err == nil {
+				t.Errorf("%d Execute() wants error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d Execute() wants no error, got: %s", i, err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("%d Execute() got %q, want %q", i, buf.String(), test.want)
+		}
+	}
+}
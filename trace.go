@@ -0,0 +1,96 @@
+package typeregistry
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tracer receives each reflection operation that the package performs while
+// tracing is on. Operations are named for what they do, such as "New" for
+// instantiating a type or "Diff" for walking the fields of two values.
+type Tracer interface {
+	TraceReflect(op string, t reflect.Type, d time.Duration)
+}
+
+type tracerBox struct {
+	Tracer
+}
+
+var tracer atomic.Value
+
+func init() {
+	tracer.Store(tracerBox{})
+}
+
+// SetTracer turns on tracing of reflection with t, or turns it off if t is
+// nil. It can be called at any time.
+func SetTracer(t Tracer) {
+	tracer.Store(tracerBox{t})
+}
+
+// trace starts timing an operation on t, and returns a function to call when
+// the operation ends. It does nothing while tracing is off.
+func trace(op string, t reflect.Type) func() {
+	tr := tracer.Load().(tracerBox).Tracer
+	if tr == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		tr.TraceReflect(op, t, time.Since(start))
+	}
+}
+
+// ReflectStat is the total count and duration of one reflection operation on
+// one type.
+type ReflectStat struct {
+	Op       string
+	Type     string
+	Count    int
+	Duration time.Duration
+}
+
+// ReflectStats is a Tracer that totals operations by type.
+type ReflectStats struct {
+	mu    sync.Mutex
+	stats map[[2]string]*ReflectStat
+}
+
+// NewReflectStats initializes an empty ReflectStats.
+func NewReflectStats() *ReflectStats {
+	return &ReflectStats{stats: make(map[[2]string]*ReflectStat)}
+}
+
+// TraceReflect adds an operation to the totals.
+func (s *ReflectStats) TraceReflect(op string, t reflect.Type, d time.Duration) {
+	key := [2]string{op, t.String()}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stat, ok := s.stats[key]
+	if !ok {
+		stat = &ReflectStat{Op: op, Type: key[1]}
+		s.stats[key] = stat
+	}
+	stat.Count++
+	stat.Duration += d
+}
+
+// Stats returns the totals, sorted by operation and type.
+func (s *ReflectStats) Stats() []ReflectStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]ReflectStat, 0, len(s.stats))
+	for _, stat := range s.stats {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Op != stats[j].Op {
+			return stats[i].Op < stats[j].Op
+		}
+		return stats[i].Type < stats[j].Type
+	})
+	return stats
+}
@@ -0,0 +1,34 @@
+package typeregistry
+
+import "testing"
+
+func TestSetTracer(t *testing.T) {
+	r := New()
+	name := r.Add(&unmarshalType{})
+
+	stats := NewReflectStats()
+	SetTracer(stats)
+	r.New(name)
+	r.Unmarshal(name, []byte("ok"), NoSetup)
+	r.DiffValues(&unmarshalType{}, &unmarshalType{})
+	SetTracer(nil)
+	r.New(name)
+
+	got := stats.Stats()
+	want := []struct {
+		op    string
+		count int
+	}{
+		{op: "Diff", count: 1},
+		{op: "New", count: 2},
+		{op: "Unmarshal", count: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Stats() got %#v, want %d stats", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Op != w.op || got[i].Type != name || got[i].Count != w.count {
+			t.Errorf("%d Stats() got %#v, want %s %s %d", i, got[i], w.op, name, w.count)
+		}
+	}
+}
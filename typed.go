@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package typeregistry
+
+import "fmt"
+
+// TypedRegistry is a view of a Registry whose types all implement T, such as
+// a common Job interface, so that values are added and returned as T without
+// type assertions.
+type TypedRegistry[T any] struct {
+	Registry Registry
+}
+
+// Typed returns a view of r whose values are of type T.
+func Typed[T any](r Registry) *TypedRegistry[T] {
+	return &TypedRegistry[T]{Registry: r}
+}
+
+// Add puts a new type in the registry. If the type cannot be registered, it
+// panics.
+func (r *TypedRegistry[T]) Add(o T) string {
+	return r.Registry.Add(o)
+}
+
+// New instantiates a type by name. If the name is unknown, or its type is not
+// a T, it panics.
+func (r *TypedRegistry[T]) New(name string) T {
+	o := r.Registry.New(name)
+	t, ok := o.(T)
+	if !ok {
+		panic(fmt.Sprintf("typeregistry %#v is %T, not %s", name, o, typeString[T]()))
+	}
+	return t
+}
+
+// Marshal encodes a value.
+func (r *TypedRegistry[T]) Marshal(o T) (string, []byte, error) {
+	return r.Registry.Marshal(o)
+}
+
+// Unmarshal decodes a type by name, calling setup, if it is not nil, before
+// the value is decoded. If the name is unknown, it panics. It returns an
+// error if the type is not a T.
+func (r *TypedRegistry[T]) Unmarshal(name string, data []byte, setup func(T)) (T, error) {
+	var notT interface{}
+	o, err := r.Registry.Unmarshal(name, data, func(o interface{}) {
+		t, ok := o.(T)
+		if !ok {
+			notT = o
+			return
+		}
+		if setup != nil {
+			setup(t)
+		}
+	})
+	t, ok := o.(T)
+	if notT != nil || !ok {
+		var zero T
+		return zero, fmt.Errorf("typeregistry %#v is %T, not %s", name, o, typeString[T]())
+	}
+	return t, err
+}
+
+// typeString returns the name of type T, which may be an interface.
+func typeString[T any]() string {
+	return fmt.Sprintf("%T", (*T)(nil))[1:]
+}
@@ -0,0 +1,63 @@
+//go:build go1.18
+// +build go1.18
+
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type job interface {
+	Run() string
+}
+
+type jobType struct {
+	JSON
+	Name string
+	svc  string
+}
+
+func (j *jobType) Run() string { return j.svc + ":" + j.Name }
+
+func TestTyped(t *testing.T) {
+	r := New()
+	jobs := Typed[job](r)
+	name := jobs.Add(&jobType{})
+	other := r.Add(&nameType{})
+
+	if got := jobs.New(name); !reflect.DeepEqual(got, &jobType{}) {
+		t.Errorf("New() got %#v, want %#v", got, &jobType{})
+	}
+
+	_, data, err := jobs.Marshal(&jobType{Name: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	got, err := jobs.Unmarshal(name, data, func(j job) {
+		j.(*jobType).svc = "svc"
+	})
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if got.Run() != "svc:x" {
+		t.Errorf("Unmarshal() got %q, want %q", got.Run(), "svc:x")
+	}
+
+	if _, err := jobs.Unmarshal(other, nil, nil); err == nil || err.Error() != `typeregistry "*typeregistry.nameType" is *typeregistry.nameType, not typeregistry.job` {
+		t.Errorf("Unmarshal() of another type got error %v", err)
+	}
+
+	var paniced string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				paniced = r.(string)
+			}
+		}()
+		jobs.New(other)
+	}()
+	if paniced != `typeregistry "*typeregistry.nameType" is *typeregistry.nameType, not typeregistry.job` {
+		t.Errorf("Expected New() of another type to panic, got %s", paniced)
+	}
+}
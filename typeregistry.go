@@ -15,6 +15,15 @@
 // If the object requires collaborators, or data from the outside world then a
 // function can be passed to Unmarshal that receives the object after it's
 // instantiated and before it's unmarshaled.
+//
+// Output derived from the registry's names, such as SaveConfig and
+// Registrar.Dump, is always sorted by name, and containers such as
+// MarshalBatch keep the order they were given, so that the same registry and
+// values always encode to the same bytes.
+//
+// Build with the typeregistry_minimal tag to leave out the optional
+// subsystems, such as the standard library encodings, for small binaries that
+// only need to instantiate types by name.
 package typeregistry
 
 import (
@@ -34,6 +43,83 @@ type Unmarshaler interface {
 	Unmarshal([]byte) error
 }
 
+// Registry is the set of operations shared by TypeRegistry and the types in
+// this package that wrap a registry to change its behavior.
+type Registry interface {
+	Add(o interface{}) string
+	New(name string) interface{}
+	Marshal(o interface{}) (string, []byte, error)
+	Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error)
+}
+
+// outerRegistry is implemented by the registries in this package, so that a
+// registry wrapped by others can pass the outermost one to RegistryMarshaler
+// and RegistryUnmarshaler values, whose nested values are then encoded through
+// every wrapper. Its appendWith method is marshalWith, but appends the data
+// to dst, and its has method reports whether a name can be unmarshaled, so
+// that unknown names can be refused without a panic.
+type outerRegistry interface {
+	marshalWith(outer Registry, o interface{}) (string, []byte, error)
+	appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error)
+	unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error)
+	has(name string) bool
+}
+
+// knows reports whether r can unmarshal name. A registry from another package
+// cannot tell, so it is assumed to.
+func knows(r Registry, name string) bool {
+	if w, ok := r.(outerRegistry); ok {
+		return w.has(name)
+	}
+	return true
+}
+
+// marshalVia marshals o with r on behalf of outer.
+func marshalVia(r, outer Registry, o interface{}) (string, []byte, error) {
+	if w, ok := r.(outerRegistry); ok {
+		return w.marshalWith(outer, o)
+	}
+	return r.Marshal(o)
+}
+
+// appendVia marshals o with r on behalf of outer, appending the data to dst.
+func appendVia(r, outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	if w, ok := r.(outerRegistry); ok {
+		return w.appendWith(outer, dst, o)
+	}
+	name, data, err := r.Marshal(o)
+	return name, append(dst, data...), err
+}
+
+// appendMarshaled is appendWith for a registry that transforms the data that
+// it marshals, and so cannot append in place.
+func appendMarshaled(w outerRegistry, outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	name, data, err := w.marshalWith(outer, o)
+	return name, append(dst, data...), err
+}
+
+// unmarshalVia unmarshals data with r on behalf of outer.
+func unmarshalVia(r, outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if w, ok := r.(outerRegistry); ok {
+		return w.unmarshalWith(outer, name, data, setup)
+	}
+	return r.Unmarshal(name, data, setup)
+}
+
+// RegistryMarshaler is implemented by types that contain values of other
+// registered types, such as a saga holding arbitrary steps, and so need the
+// registry to encode them. It takes precedence over Marshaler.
+type RegistryMarshaler interface {
+	MarshalWithRegistry(r Registry) ([]byte, error)
+}
+
+// RegistryUnmarshaler is the counterpart of RegistryMarshaler, for types that
+// need the registry to decode the values they contain. It takes precedence
+// over Unmarshaler.
+type RegistryUnmarshaler interface {
+	UnmarshalWithRegistry(r Registry, data []byte) error
+}
+
 // TypeRegistry can instantiate, marshal, and unmarshal types from string names
 // and type-defined encodings.
 type TypeRegistry map[string]reflect.Type
@@ -43,20 +129,64 @@ func New() TypeRegistry {
 	return make(TypeRegistry)
 }
 
-// Add puts a new type in the registry. If the type cannot be registered, it
-// panics. It returns the name that it was registered as.
+// Add puts a new type in the registry. If the type cannot be registered, or
+// another type is already registered as its name, it panics, naming where
+// both types were added. Channels, functions, and unsafe pointers cannot be
+// registered, because New could only make nil values of them. It returns the
+// name that it was registered as, which is the type's TypeName if it is a
+// TypeNamer.
 func (r TypeRegistry) Add(o interface{}) string {
-	if o == nil {
-		panic("typeregistry cannot add nil")
-	}
+	mustAdd(o)
 	name := r.name(o)
-	r[name] = reflect.TypeOf(o)
+	t := reflect.TypeOf(o)
+	if err := r.checkName(name, t); err != nil {
+		panic(err.Error())
+	}
+	record(t)
+	r[name] = t
 	return name
 }
 
+// checkName returns an error if a type other than t is registered as name,
+// naming where each was added.
+func (r TypeRegistry) checkName(name string, t reflect.Type) error {
+	if other, ok := r[name]; ok && other != t {
+		return fmt.Errorf("typeregistry cannot add %s as %#v, it is already %s (adding from %s, %s first added from %s)", t, name, other, outsideCaller(), other, siteOf(other))
+	}
+	return nil
+}
+
+// record computes what the registry keeps about t when it is added, such as
+// its Capabilities, module version, and the call site that added it.
+func record(t reflect.Type) {
+	capabilitiesOf(t)
+	moduleVersionOf(t)
+	recordSite(t)
+}
+
+// mustAdd panics if o cannot be registered.
+func mustAdd(o interface{}) {
+	if err := checkAdd(o); err != nil {
+		panic(err.Error())
+	}
+}
+
+// checkAdd returns an error if o cannot be registered.
+func checkAdd(o interface{}) error {
+	if o == nil {
+		return ErrNilType
+	}
+	switch k := reflect.TypeOf(o).Kind(); k {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return fmt.Errorf("typeregistry cannot add %T of kind %s", o, k)
+	}
+	return nil
+}
+
 // New instantiates a type by name. If the name is unknown, it panics.
 func (r TypeRegistry) New(name string) interface{} {
 	if val, ok := r[name]; ok {
+		defer trace("New", val)()
 		if val.Kind() == reflect.Ptr {
 			v := reflect.New(val.Elem())
 			return v.Interface()
@@ -64,20 +194,37 @@ func (r TypeRegistry) New(name string) interface{} {
 		v := reflect.New(val).Elem()
 		return v.Interface()
 	}
-	panic(fmt.Sprintf("typeregistry does not know %#v", name))
+	panic(ErrUnknownType{Name: name}.Error())
 }
 
-// Marshal encodes a type. If the type implements Marshaler or its bytes are
-// returned.
+// Marshal encodes a type. If the type implements Marshaler its bytes are
+// returned. Standard library types added by AddStdlib use their built-in
+// encodings, an Enum is encoded as its string value, and a struct embedding
+// JSON is encoded with encoding/json. Other types fall back to
+// encoding.BinaryMarshaler, encoding.TextMarshaler, then json.Marshaler. An
+// *Unknown returns its original name and data.
+// A type without an encoding, or whose encoding is empty, returns nil data,
+// never an empty slice. A type that supports more than one encoding uses the
+// first in the order of the Encoding constants. A Normalizer is normalized
+// before it is encoded, and a NameSelector chooses its own name.
 func (r TypeRegistry) Marshal(o interface{}) (string, []byte, error) {
-	var (
-		name  = r.name(o)
-		bytes []byte
-		err   error
-	)
-	switch m := o.(type) {
-	case Marshaler:
-		bytes, err = m.Marshal()
+	return r.marshalWith(r, o)
+}
+
+func (r TypeRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	if u, ok := o.(*Unknown); ok {
+		return u.Name, u.Data, nil
+	}
+	name, err := r.marshalName(o)
+	if err != nil {
+		return name, nil, err
+	}
+	if err := normalize(o); err != nil {
+		return name, nil, err
+	}
+	bytes, err := r.marshalAs(outer, o, marshalEncoding(o))
+	if len(bytes) == 0 {
+		bytes = nil
 	}
 	return name, bytes, err
 }
@@ -93,23 +240,45 @@ type SetupFunc func(interface{})
 var NoSetup = func(i interface{}) {}
 
 // Unmarshal decodes a type by name. If the type implements Unmarshaler, the
-// data is used to unmarshal. SetupFunc can be passed to inject any other data
-// into the type before it is unmarshaled.
+// data is used to unmarshal. An Enum is decoded from its string value, and a
+// struct embedding JSON is decoded with encoding/json. Other types fall back to
+// encoding.BinaryUnmarshaler, encoding.TextUnmarshaler, then json.Unmarshaler,
+// which are not called for empty data. SetupFunc can be passed to inject any
+// other data into the type before it is unmarshaled. Empty data is passed on
+// as nil, so nil and empty data decode the same way. A type that supports
+// more than one encoding uses the first in the order of the Encoding
+// constants.
 func (r TypeRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return r.unmarshalWith(r, name, data, setup)
+}
+
+func (r TypeRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if len(data) == 0 {
+		data = nil
+	}
 	instance := r.New(name)
 	if setup != nil {
 		setup(instance)
 	}
-	switch m := instance.(type) {
-	case Unmarshaler:
-		if err := m.Unmarshal(data); err != nil {
-			return instance, err
-		}
-	}
-	return instance, nil
+	return r.unmarshalAs(outer, instance, data, unmarshalEncoding(instance))
+}
+
+func (r TypeRegistry) has(name string) bool {
+	_, ok := r[name]
+	return ok
+}
+
+// TypeNamer is implemented by types that choose the name they are added and
+// marshaled as, so that stored data does not depend on the Go package layout.
+// It must return the same name for every value of the type, including the
+// zero value.
+type TypeNamer interface {
+	TypeName() string
 }
 
 func (r TypeRegistry) name(c interface{}) string {
-	// TODO: let types set their own name?
+	if n, ok := c.(TypeNamer); ok {
+		return n.TypeName()
+	}
 	return reflect.TypeOf(c).String()
 }
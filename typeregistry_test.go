@@ -2,9 +2,12 @@ package typeregistry
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
+	"unsafe"
 )
 
 type nothingType struct {
@@ -38,6 +41,13 @@ func (m *unmarshalType) Unmarshal(data []byte) error {
 type unmarshalFailType struct {
 }
 
+type namedType struct {
+	JSON
+	Name string
+}
+
+func (*namedType) TypeName() string { return "example.named" }
+
 func (m *unmarshalFailType) Unmarshal(data []byte) error {
 	return fmt.Errorf("Failed")
 }
@@ -62,6 +72,14 @@ func TestTypeRegistry_Add(t *testing.T) {
 			t:    &nothingType{},
 			want: "*typeregistry.nothingType",
 		},
+		{
+			t:    namedType{},
+			want: "typeregistry.namedType",
+		},
+		{
+			t:    &namedType{},
+			want: "example.named",
+		},
 	}
 	for i, test := range tests {
 		r := make(TypeRegistry)
@@ -87,6 +105,72 @@ func TestTypeRegistry_Add(t *testing.T) {
 	}
 }
 
+func TestTypeRegistry_Add_kinds(t *testing.T) {
+	var (
+		i  int
+		ip = &i
+		r  io.Reader
+	)
+	tests := []struct {
+		t     interface{}
+		kind  reflect.Kind
+		panic bool
+	}{
+		{t: false, kind: reflect.Bool},
+		{t: int(0), kind: reflect.Int},
+		{t: int8(0), kind: reflect.Int8},
+		{t: int16(0), kind: reflect.Int16},
+		{t: int32(0), kind: reflect.Int32},
+		{t: int64(0), kind: reflect.Int64},
+		{t: uint(0), kind: reflect.Uint},
+		{t: uint8(0), kind: reflect.Uint8},
+		{t: uint16(0), kind: reflect.Uint16},
+		{t: uint32(0), kind: reflect.Uint32},
+		{t: uint64(0), kind: reflect.Uint64},
+		{t: uintptr(0), kind: reflect.Uintptr},
+		{t: float32(0), kind: reflect.Float32},
+		{t: float64(0), kind: reflect.Float64},
+		{t: complex64(0), kind: reflect.Complex64},
+		{t: complex128(0), kind: reflect.Complex128},
+		{t: [2]int{}, kind: reflect.Array},
+		{t: make(chan int), kind: reflect.Chan, panic: true},
+		{t: func() {}, kind: reflect.Func, panic: true},
+		{t: &r, kind: reflect.Ptr},
+		{t: map[string]int{}, kind: reflect.Map},
+		{t: ip, kind: reflect.Ptr},
+		{t: []int{}, kind: reflect.Slice},
+		{t: "", kind: reflect.String},
+		{t: nothingType{}, kind: reflect.Struct},
+		{t: unsafe.Pointer(ip), kind: reflect.UnsafePointer, panic: true},
+	}
+	for i, test := range tests {
+		if k := reflect.TypeOf(test.t).Kind(); k != test.kind {
+			t.Fatalf("%d kind got %s, want %s", i, k, test.kind)
+		}
+		r := make(TypeRegistry)
+		var paniced string
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					paniced = r.(string)
+				}
+			}()
+			name := r.Add(test.t)
+			if got := reflect.TypeOf(r.New(name)); got != reflect.TypeOf(test.t) {
+				t.Errorf("%d New(%s) got %s", i, name, got)
+			}
+		}()
+		if test.panic {
+			want := fmt.Sprintf("typeregistry cannot add %T of kind %s", test.t, test.kind)
+			if paniced != want {
+				t.Errorf("%d Add(%T) got panic %q, want %q", i, test.t, paniced, want)
+			}
+		} else if paniced != "" {
+			t.Errorf("%d Add(%T) wants no panic, got %s", i, test.t, paniced)
+		}
+	}
+}
+
 func TestTypeRegistry_New(t *testing.T) {
 	tests := []struct {
 		t    interface{}
@@ -179,6 +263,51 @@ func TestTypeRegistry_Marshal(t *testing.T) {
 		if !bytes.Equal(val, test.val) {
 			t.Errorf("%d Marshal() value: got %#v, want %#v", i, val, test.val)
 		}
+		if len(val) == 0 && val != nil {
+			t.Errorf("%d Marshal() value: got %#v, want nil", i, val)
+		}
+	}
+}
+
+type dataType struct {
+	Data []byte
+}
+
+func (d *dataType) Unmarshal(data []byte) error {
+	d.Data = data
+	return nil
+}
+
+func TestTypeRegistry_TypeNamer(t *testing.T) {
+	r := New()
+	r.Add(&namedType{})
+	name, data, err := r.Marshal(&namedType{Name: "x"})
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if name != "example.named" {
+		t.Errorf("Marshal() got name %q, want %q", name, "example.named")
+	}
+	got, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&namedType{Name: "x"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+}
+
+func TestTypeRegistry_Unmarshal_empty(t *testing.T) {
+	r := New()
+	name := r.Add(&dataType{})
+	for i, data := range [][]byte{nil, {}} {
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if d := got.(*dataType); d.Data != nil {
+			t.Errorf("%d Unmarshal() data got %#v, want nil", i, d.Data)
+		}
 	}
 }
 
@@ -248,3 +377,67 @@ func TestTypeRegistry_Unmarshal(t *testing.T) {
 		}
 	}
 }
+
+// sagaType holds steps of any registered type.
+type sagaType struct {
+	Steps []interface{}
+}
+
+type sagaStep struct {
+	Name string
+	Data []byte
+}
+
+func (s *sagaType) MarshalWithRegistry(r Registry) ([]byte, error) {
+	steps := make([]sagaStep, len(s.Steps))
+	for i, o := range s.Steps {
+		name, data, err := r.Marshal(o)
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = sagaStep{name, data}
+	}
+	return json.Marshal(steps)
+}
+
+func (s *sagaType) UnmarshalWithRegistry(r Registry, data []byte) error {
+	var steps []sagaStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return err
+	}
+	for _, step := range steps {
+		o, err := r.Unmarshal(step.Name, step.Data, NoSetup)
+		if err != nil {
+			return err
+		}
+		s.Steps = append(s.Steps, o)
+	}
+	return nil
+}
+
+func TestTypeRegistry_RegistryMarshaler(t *testing.T) {
+	r := New()
+	name := r.Add(&sagaType{})
+	r.Add(&unmarshalType{})
+	r.Add(marshalType{})
+
+	saga := &sagaType{Steps: []interface{}{&unmarshalType{}, marshalType{Name: "ok"}}}
+	mname, data, err := r.Marshal(saga)
+	if err != nil {
+		t.Fatalf("Marshal() wants no error, got: %s", err)
+	}
+	if mname != name {
+		t.Errorf("Marshal() name got %s, want %s", mname, name)
+	}
+	got, err := r.Unmarshal(name, data, NoSetup)
+	if err != nil {
+		t.Fatalf("Unmarshal() wants no error, got: %s", err)
+	}
+	want := &sagaType{Steps: []interface{}{&unmarshalType{Name: "bin:"}, marshalType{}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	if _, err := r.Unmarshal(name, []byte("{"), NoSetup); err == nil {
+		t.Errorf("Unmarshal() of bad data wants error, got none")
+	}
+}
@@ -0,0 +1,39 @@
+// Package typeregistrytest provides helpers for testing code that uses
+// typeregistry.
+package typeregistrytest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+// Contract checks that the consumer can unmarshal everything the producer
+// can marshal, as a consumer-driven contract test. For each type in the
+// producer it marshals a value filled by Fake and unmarshals it with the
+// consumer, reporting every type that fails.
+func Contract(t testing.TB, producer typeregistry.TypeRegistry, consumer typeregistry.Registry) {
+	t.Helper()
+	for _, name := range producer.Names() {
+		o := producer.Fake(name, 1)
+		pname, data, err := producer.Marshal(o)
+		if err != nil {
+			t.Errorf("producer cannot marshal %s: %s", name, err)
+			continue
+		}
+		if err := unmarshal(consumer, pname, data); err != nil {
+			t.Errorf("consumer cannot unmarshal %s: %s", pname, err)
+		}
+	}
+}
+
+func unmarshal(r typeregistry.Registry, name string, data []byte) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+	_, err = r.Unmarshal(name, data, typeregistry.NoSetup)
+	return err
+}
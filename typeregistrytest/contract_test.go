@@ -0,0 +1,70 @@
+package typeregistrytest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+type order struct {
+	ID string
+}
+
+type strictOrder struct {
+	ID string
+}
+
+func (o *strictOrder) Unmarshal(data []byte) error {
+	return errors.New("rejected")
+}
+
+type shipment struct{}
+
+// recorder is a testing.TB that records errors instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestContract(t *testing.T) {
+	producer := typeregistry.New()
+	producer.Add(&order{})
+	producer.Add(&shipment{})
+
+	tests := []struct {
+		consumer typeregistry.TypeRegistry
+		want     []string
+	}{
+		{
+			consumer: producer.Subset("*typeregistrytest.order", "*typeregistrytest.shipment"),
+			want:     nil,
+		},
+		{
+			consumer: producer.Subset("*typeregistrytest.order"),
+			want:     []string{`consumer cannot unmarshal *typeregistrytest.shipment: typeregistry does not know "*typeregistrytest.shipment"`},
+		},
+		{
+			consumer: typeregistry.TypeRegistry{
+				"*typeregistrytest.order":    reflect.TypeOf(&strictOrder{}),
+				"*typeregistrytest.shipment": reflect.TypeOf(&shipment{}),
+			},
+			want: []string{"consumer cannot unmarshal *typeregistrytest.order: rejected"},
+		},
+	}
+	for i, test := range tests {
+		rec := &recorder{TB: t}
+		Contract(rec, producer, test.consumer)
+		if !reflect.DeepEqual(rec.errors, test.want) {
+			t.Errorf("%d Contract() got errors %#v, want %#v", i, rec.errors, test.want)
+		}
+	}
+}
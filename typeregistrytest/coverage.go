@@ -0,0 +1,145 @@
+package typeregistrytest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/rcarver/typeregistry"
+)
+
+// Kind is a kind of test that Coverage tracks.
+type Kind string
+
+const (
+	// RoundTrip tests are recorded by Coverage.RoundTrip.
+	RoundTrip Kind = "roundtrip"
+	// Golden tests are recorded by Coverage.Golden.
+	Golden Kind = "golden"
+)
+
+var kinds = []Kind{RoundTrip, Golden}
+
+// UpdateGolden makes Coverage.Golden write the golden file instead of
+// comparing against it. Set it from a flag in your tests.
+var UpdateGolden bool
+
+// Coverage records which kinds of test have been run for each type in a
+// registry, so that a suite can report on, and require, tests for every type
+// as the registry grows. Share one Coverage between the tests of a package
+// and check it after they have all run, such as in TestMain.
+type Coverage struct {
+	r       typeregistry.TypeRegistry
+	mu      sync.Mutex
+	covered map[string]map[Kind]bool
+}
+
+// NewCoverage initializes a Coverage of the types in r.
+func NewCoverage(r typeregistry.TypeRegistry) *Coverage {
+	return &Coverage{r: r, covered: make(map[string]map[Kind]bool)}
+}
+
+// RoundTrip checks that o marshals, unmarshals, and marshals again to the
+// same data, and records a round-trip test for its type.
+func (c *Coverage) RoundTrip(t testing.TB, o interface{}) {
+	t.Helper()
+	name, data, err := c.r.Marshal(o)
+	if err != nil {
+		t.Errorf("cannot marshal %s: %s", name, err)
+		return
+	}
+	c.record(name, RoundTrip)
+	got, err := c.r.Unmarshal(name, data, typeregistry.NoSetup)
+	if err != nil {
+		t.Errorf("cannot unmarshal %s: %s", name, err)
+		return
+	}
+	_, again, err := c.r.Marshal(got)
+	if err != nil {
+		t.Errorf("cannot marshal %s again: %s", name, err)
+		return
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("%s round trip got %q, want %q", name, again, data)
+	}
+}
+
+// Golden checks that o marshals to the contents of the file at path, and
+// records a golden test for its type. If UpdateGolden is set, it writes the
+// file instead.
+func (c *Coverage) Golden(t testing.TB, o interface{}, path string) {
+	t.Helper()
+	name, data, err := c.r.Marshal(o)
+	if err != nil {
+		t.Errorf("cannot marshal %s: %s", name, err)
+		return
+	}
+	c.record(name, Golden)
+	if UpdateGolden {
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Errorf("cannot write golden file for %s: %s", name, err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("cannot read golden file for %s: %s", name, err)
+		return
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("%s got %q, want golden %q", name, data, want)
+	}
+}
+
+func (c *Coverage) record(name string, k Kind) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.covered[name] == nil {
+		c.covered[name] = make(map[Kind]bool)
+	}
+	c.covered[name][k] = true
+}
+
+// Report writes a table of every registered type and the kinds of test that
+// have been recorded for it to w, in order of name.
+func (c *Coverage) Report(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "type")
+	for _, k := range kinds {
+		fmt.Fprintf(tw, "\t%s", k)
+	}
+	fmt.Fprintln(tw)
+	for _, name := range c.r.Names() {
+		fmt.Fprint(tw, name)
+		for _, k := range kinds {
+			mark := "-"
+			if c.covered[name][k] {
+				mark = "yes"
+			}
+			fmt.Fprintf(tw, "\t%s", mark)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// Require reports an error for each registered type that is missing any of
+// the kinds of test.
+func (c *Coverage) Require(t testing.TB, kinds ...Kind) {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range c.r.Names() {
+		for _, k := range kinds {
+			if !c.covered[name][k] {
+				t.Errorf("%s has no %s test", name, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package typeregistrytest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rcarver/typeregistry"
+)
+
+type flaky struct {
+	N int
+}
+
+func (f *flaky) Marshal() ([]byte, error) {
+	return []byte{byte(f.N)}, nil
+}
+
+func (f *flaky) Unmarshal(data []byte) error {
+	f.N = int(data[0]) + 1
+	return nil
+}
+
+func TestCoverage(t *testing.T) {
+	r := typeregistry.New()
+	r.Add(&order{})
+	r.Add(&shipment{})
+	r.Add(&flaky{})
+	c := NewCoverage(r)
+
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "order.golden")
+
+	rec := &recorder{TB: t}
+	c.RoundTrip(rec, &order{ID: "a"})
+	c.RoundTrip(rec, &flaky{N: 1})
+	UpdateGolden = true
+	c.Golden(rec, &shipment{}, golden)
+	UpdateGolden = false
+	c.Golden(rec, &shipment{}, golden)
+	c.Golden(rec, &order{}, filepath.Join(dir, "missing.golden"))
+	want := []string{
+		"*typeregistrytest.flaky round trip got \"\\x02\", want \"\\x01\"",
+		"cannot read golden file for *typeregistrytest.order: open " + filepath.Join(dir, "missing.golden") + ": no such file or directory",
+	}
+	if !reflect.DeepEqual(rec.errors, want) {
+		t.Errorf("got errors %#v, want %#v", rec.errors, want)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Report(&buf); err != nil {
+		t.Fatalf("Report() wants no error, got: %s", err)
+	}
+	report := "type                        roundtrip  golden\n" +
+		"*typeregistrytest.flaky     yes        -\n" +
+		"*typeregistrytest.order     yes        yes\n" +
+		"*typeregistrytest.shipment  -          yes\n"
+	if buf.String() != report {
+		t.Errorf("Report() got %q, want %q", buf.String(), report)
+	}
+
+	rec = &recorder{TB: t}
+	c.Require(rec, RoundTrip)
+	if want := []string{"*typeregistrytest.shipment has no roundtrip test"}; !reflect.DeepEqual(rec.errors, want) {
+		t.Errorf("Require() got errors %#v, want %#v", rec.errors, want)
+	}
+}
@@ -0,0 +1,42 @@
+package typeregistry
+
+// Unknown holds data whose name a registry does not know, so that services
+// which forward data they do not understand can pass it along losslessly.
+// Marshal returns its Name and Data exactly as they were unmarshaled.
+type Unknown struct {
+	Name string
+	Data []byte
+}
+
+// Passthrough returns a view of the registry that returns an *Unknown for
+// names it does not know, instead of panicking, from New and Unmarshal.
+func (r TypeRegistry) Passthrough() Registry {
+	return passthrough{r}
+}
+
+type passthrough struct {
+	TypeRegistry
+}
+
+func (p passthrough) New(name string) interface{} {
+	if _, ok := p.TypeRegistry[name]; !ok {
+		return &Unknown{Name: name}
+	}
+	return p.TypeRegistry.New(name)
+}
+
+func (p passthrough) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return p.unmarshalWith(p, name, data, setup)
+}
+
+func (p passthrough) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	if _, ok := p.TypeRegistry[name]; !ok {
+		return &Unknown{Name: name, Data: data}, nil
+	}
+	return p.TypeRegistry.unmarshalWith(outer, name, data, setup)
+}
+
+// has is true for every name, since unknown names unmarshal as Unknown.
+func (p passthrough) has(name string) bool {
+	return true
+}
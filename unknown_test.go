@@ -0,0 +1,40 @@
+package typeregistry
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_Passthrough(t *testing.T) {
+	r := New()
+	r.Add(&unmarshalType{})
+	p := r.Passthrough()
+
+	got, err := p.Unmarshal("*typeregistry.unmarshalType", []byte("ok"), NoSetup)
+	if err != nil {
+		t.Errorf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&unmarshalType{Name: "bin:ok"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+
+	data := []byte{0, 1, 2, 0xff}
+	got, err = p.Unmarshal("other.Thing", data, NoSetup)
+	if err != nil {
+		t.Errorf("Unmarshal() wants no error, got: %s", err)
+	}
+	if want := (&Unknown{Name: "other.Thing", Data: data}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got %#v, want %#v", got, want)
+	}
+	name, mdata, err := r.Marshal(got)
+	if err != nil {
+		t.Errorf("Marshal() wants no error, got: %s", err)
+	}
+	if name != "other.Thing" || !bytes.Equal(mdata, data) {
+		t.Errorf("Marshal() got %s %#v, want %s %#v", name, mdata, "other.Thing", data)
+	}
+	if got := p.New("other.Thing"); !reflect.DeepEqual(got, &Unknown{Name: "other.Thing"}) {
+		t.Errorf("New() got %#v, want *Unknown", got)
+	}
+}
@@ -0,0 +1,80 @@
+package typeregistry
+
+import (
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// readBuildInfo is replaced in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// versionCache holds the module version of each type, recorded when it is
+// added.
+var versionCache sync.Map
+
+// ModuleVersion returns the version of the module that defines the type
+// registered as name, as recorded from the running binary's build information
+// when the type was added. Knowing which version of a type produced some
+// stored data helps when debugging it. It returns "" if the name is unknown
+// or no version is recorded, such as for standard library types or binaries
+// built outside of module mode. SaveConfig includes it, and
+// EncodeVersionedEnvelope puts it in envelopes.
+func (r TypeRegistry) ModuleVersion(name string) string {
+	t, ok := r[name]
+	if !ok {
+		return ""
+	}
+	return moduleVersionOf(t)
+}
+
+// moduleVersionOf returns the module version of t, recording it the first
+// time.
+func moduleVersionOf(t reflect.Type) string {
+	if v, ok := versionCache.Load(t); ok {
+		return v.(string)
+	}
+	_, version := moduleOf(pkgPath(t))
+	versionCache.Store(t, version)
+	return version
+}
+
+// moduleOf returns the path and version of the module that provides package
+// pkg in the running binary, or "" if it is not known.
+func moduleOf(pkg string) (path, version string) {
+	info, ok := readBuildInfo()
+	if !ok || pkg == "" {
+		return "", ""
+	}
+	var found *debug.Module
+	for _, m := range append([]*debug.Module{&info.Main}, info.Deps...) {
+		if pkg != m.Path && !strings.HasPrefix(pkg, m.Path+"/") {
+			continue
+		}
+		if found == nil || len(m.Path) > len(found.Path) {
+			found = m
+		}
+	}
+	if found == nil {
+		return "", ""
+	}
+	if found.Replace != nil && found.Replace.Version != "" {
+		return found.Path, found.Replace.Version
+	}
+	return found.Path, found.Version
+}
+
+// pkgPath returns the import path of the package that defines t, looking
+// through pointers and containers of unnamed types.
+func pkgPath(t reflect.Type) string {
+	for t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+			t = t.Elem()
+		default:
+			return ""
+		}
+	}
+	return t.PkgPath()
+}
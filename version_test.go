@@ -0,0 +1,101 @@
+package typeregistry
+
+import (
+	"reflect"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+// testBuildInfo is build information in which this package is v1.2.3.
+var testBuildInfo = &debug.BuildInfo{
+	Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+	Deps: []*debug.Module{
+		{Path: "github.com/rcarver", Version: "v0.1.0"},
+		{Path: "github.com/rcarver/typeregistry", Version: "v1.2.3"},
+		{Path: "github.com/rcarver/typeregistryx", Version: "v9.9.9"},
+	},
+}
+
+// withBuildInfo makes info the build information of the running binary, or
+// none if it is nil, and forgets the module versions recorded so far. The
+// returned function restores them.
+func withBuildInfo(info *debug.BuildInfo) func() {
+	f := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return info, info != nil }
+	forget := func() {
+		versionCache.Range(func(k, _ interface{}) bool {
+			versionCache.Delete(k)
+			return true
+		})
+	}
+	forget()
+	return func() {
+		readBuildInfo = f
+		forget()
+	}
+}
+
+func TestTypeRegistry_ModuleVersion(t *testing.T) {
+	defer withBuildInfo(testBuildInfo)()
+
+	r := New()
+	r.Add(&nothingType{})
+	r.Add([]nameType{})
+	r.Add(time.Time{})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "*typeregistry.nothingType", want: "v1.2.3"},
+		{name: "[]typeregistry.nameType", want: "v1.2.3"},
+		{name: "time.Time", want: ""},
+		{name: "unknown", want: ""},
+	}
+	for i, test := range tests {
+		if got := r.ModuleVersion(test.name); got != test.want {
+			t.Errorf("%d ModuleVersion(%s) got %q, want %q", i, test.name, got, test.want)
+		}
+	}
+}
+
+func TestTypeRegistry_ModuleVersion_recorded(t *testing.T) {
+	defer withBuildInfo(testBuildInfo)()
+	r := New()
+	name := r.Add(&nothingType{})
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	if got := r.ModuleVersion(name); got != "v1.2.3" {
+		t.Errorf("ModuleVersion() got %q, want the version recorded by Add", got)
+	}
+
+	data, err := r.SaveConfig()
+	if err != nil {
+		t.Fatalf("SaveConfig() wants no error, got: %s", err)
+	}
+	want := `[{"name":"*typeregistry.nothingType","type":"github.com/rcarver/typeregistry *typeregistry.nothingType","version":"v1.2.3"}]`
+	if string(data) != want {
+		t.Errorf("SaveConfig() got %s, want %s", data, want)
+	}
+
+	data, err = r.EncodeVersionedEnvelope(&nothingType{})
+	if err != nil {
+		t.Fatalf("EncodeVersionedEnvelope() wants no error, got: %s", err)
+	}
+	if want := "\x02\x06v1.2.3\x19*typeregistry.nothingType\x00"; string(data) != want {
+		t.Errorf("EncodeVersionedEnvelope() got %q, want %q", data, want)
+	}
+	var e Envelope
+	if err := e.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() wants no error, got: %s", err)
+	}
+	if want := (Envelope{Name: name, Version: "v1.2.3", Data: []byte{}}); !reflect.DeepEqual(e, want) {
+		t.Errorf("UnmarshalBinary() got %#v, want %#v", e, want)
+	}
+	got, err := r.DecodeEnvelope(data, NoSetup)
+	if err != nil {
+		t.Fatalf("DecodeEnvelope() wants no error, got: %s", err)
+	}
+	if want := (&nothingType{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeEnvelope() got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package typeregistry
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Viewer is implemented by any type that can read its marshaled data in
+// place, returning an accessor over the buffer instead of decoding a copy, as
+// with formats like FlatBuffers or Cap'n Proto. The accessor may refer to the
+// data, so the caller must not modify the data while the accessor is in use.
+type Viewer interface {
+	View(data []byte) (interface{}, error)
+}
+
+var viewerType = reflect.TypeOf((*Viewer)(nil)).Elem()
+
+// CanView reports whether the type registered as name implements Viewer.
+func (r TypeRegistry) CanView(name string) bool {
+	t, ok := r[name]
+	return ok && t.Implements(viewerType)
+}
+
+// View returns an accessor over data for the type registered as name, which
+// must implement Viewer. If the name is unknown, it panics.
+func (r TypeRegistry) View(name string, data []byte) (interface{}, error) {
+	v, ok := r.New(name).(Viewer)
+	if !ok {
+		return nil, fmt.Errorf("typeregistry %s does not implement Viewer", name)
+	}
+	return v.View(data)
+}
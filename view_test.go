@@ -0,0 +1,46 @@
+package typeregistry
+
+import (
+	"bytes"
+	"testing"
+)
+
+// viewType returns an accessor over its data without copying it.
+type viewType struct{}
+
+type viewAccessor struct {
+	data []byte
+}
+
+func (v viewType) View(data []byte) (interface{}, error) {
+	return viewAccessor{data}, nil
+}
+
+func TestTypeRegistry_View(t *testing.T) {
+	r := New()
+	view := r.Add(viewType{})
+	other := r.Add(nameType{})
+
+	if !r.CanView(view) {
+		t.Errorf("CanView(%s) got false, want true", view)
+	}
+	if r.CanView(other) {
+		t.Errorf("CanView(%s) got true, want false", other)
+	}
+	if r.CanView("unknown") {
+		t.Errorf("CanView(unknown) got true, want false")
+	}
+
+	data := []byte("bin:ok")
+	got, err := r.View(view, data)
+	if err != nil {
+		t.Fatalf("View() wants no error, got: %s", err)
+	}
+	a := got.(viewAccessor)
+	if !bytes.Equal(a.data, data) || &a.data[0] != &data[0] {
+		t.Errorf("View() got %#v, want an accessor over the original data", a)
+	}
+	if _, err := r.View(other, data); err == nil {
+		t.Errorf("View(%s) wants error, got none", other)
+	}
+}
@@ -0,0 +1,45 @@
+package typeregistry
+
+import "fmt"
+
+// Warmup instantiates, marshals, and unmarshals a new value of every
+// registered type once, so that lazily built caches are ready before the
+// first real request, and any type that panics does so at startup rather
+// than on live traffic. Errors from marshaling zero values are expected, such
+// as for an Enum whose zero value is not allowed, and are ignored. It returns
+// a BatchError holding a failure for each type that panicked, in order of
+// name.
+func (r TypeRegistry) Warmup() error {
+	names := r.Names()
+	var errs BatchError
+	for i, name := range names {
+		if err := r.warmup(name); err != nil {
+			errs = append(errs, &ItemError{Index: i, Name: name, Err: err})
+		}
+	}
+	if errs != nil {
+		return errs
+	}
+	return nil
+}
+
+func (r TypeRegistry) warmup(name string) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	capabilitiesOf(r[name])
+	o := r.New(name)
+	if v, ok := structOf(o); ok && marshalEncoding(o) == EncodingJSON {
+		if _, err := fieldCodecsOf(v.Type()); err != nil {
+			return err
+		}
+	}
+	_, data, err := r.Marshal(o)
+	if err != nil {
+		return nil
+	}
+	r.Unmarshal(name, data, NoSetup)
+	return nil
+}
@@ -0,0 +1,37 @@
+package typeregistry
+
+import (
+	"errors"
+	"testing"
+)
+
+type panicType struct{}
+
+func (panicType) Marshal() ([]byte, error) {
+	panic("boom")
+}
+
+func TestTypeRegistry_Warmup(t *testing.T) {
+	r := New()
+	r.Add(nothingType{})
+	r.Add(&unmarshalType{})
+	r.Add(colorType(""))
+	r.Add(&jsonType{})
+	if err := r.Warmup(); err != nil {
+		t.Errorf("Warmup() wants no error, got: %s", err)
+	}
+
+	r.Add(panicType{})
+	r.Add(badTagType{})
+	err := r.Warmup()
+	var errs BatchError
+	if !errors.As(err, &errs) {
+		t.Fatalf("Warmup() got %v, want a BatchError", err)
+	}
+	if len(errs) != 2 || errs[0].Name != "typeregistry.badTagType" || errs[1].Name != "typeregistry.panicType" {
+		t.Errorf("Warmup() got %v, want errors for badTagType and panicType", err)
+	}
+	if got, want := errs[1].Err.Error(), "panic: boom"; got != want {
+		t.Errorf("Warmup() error got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package typeregistry
+
+import (
+	"encoding/json"
+)
+
+// ZeroPolicy controls how a ZeroRegistry encodes the fields of a struct
+// embedding JSON that hold their zero value.
+type ZeroPolicy int
+
+const (
+	// ZeroInclude encodes zero values as encoding/json does.
+	ZeroInclude ZeroPolicy = iota
+	// ZeroOmit leaves zero values out, as if every field were tagged
+	// omitempty.
+	ZeroOmit
+	// ZeroNull encodes zero values as null.
+	ZeroNull
+)
+
+// ZeroRegistry is a Registry that applies one ZeroPolicy to every struct
+// embedding JSON, instead of relying on the json tags of each one. The policy
+// applies to the struct's own fields, not to those of embedded structs.
+// Omitted and null fields both unmarshal as zero values.
+type ZeroRegistry struct {
+	TypeRegistry TypeRegistry
+	Policy       ZeroPolicy
+}
+
+// WithZeroPolicy wraps a registry so that zero values are encoded by policy.
+func WithZeroPolicy(r TypeRegistry, policy ZeroPolicy) *ZeroRegistry {
+	return &ZeroRegistry{TypeRegistry: r, Policy: policy}
+}
+
+// Add puts a new type in the registry.
+func (z *ZeroRegistry) Add(o interface{}) string {
+	return z.TypeRegistry.Add(o)
+}
+
+// New instantiates a type by name. If the name is unknown, it panics.
+func (z *ZeroRegistry) New(name string) interface{} {
+	return z.TypeRegistry.New(name)
+}
+
+// Marshal encodes a type, applying the policy if it embeds JSON.
+func (z *ZeroRegistry) Marshal(o interface{}) (string, []byte, error) {
+	return z.marshalWith(z, o)
+}
+
+func (z *ZeroRegistry) marshalWith(outer Registry, o interface{}) (string, []byte, error) {
+	name, data, err := z.TypeRegistry.marshalWith(outer, o)
+	if err != nil || z.Policy == ZeroInclude || marshalEncoding(o) != EncodingJSON {
+		return name, data, err
+	}
+	v, ok := structOf(o)
+	if !ok {
+		return name, data, nil
+	}
+	zero := make(map[string]bool)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" || f.Anonymous || !v.Field(i).IsZero() {
+			continue
+		}
+		if key, ok := jsonKey(f); ok {
+			zero[key] = true
+		}
+	}
+	members, err := readObject(data)
+	if err != nil {
+		return name, nil, err
+	}
+	kept := members[:0]
+	for _, m := range members {
+		if zero[m.key] {
+			if z.Policy == ZeroOmit {
+				continue
+			}
+			m.value = json.RawMessage("null")
+		}
+		kept = append(kept, m)
+	}
+	return name, writeObject(kept), nil
+}
+
+func (z *ZeroRegistry) appendWith(outer Registry, dst []byte, o interface{}) (string, []byte, error) {
+	return appendMarshaled(z, outer, dst, o)
+}
+
+// Unmarshal decodes a type by name. If the name is unknown, it panics.
+func (z *ZeroRegistry) Unmarshal(name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return z.unmarshalWith(z, name, data, setup)
+}
+
+func (z *ZeroRegistry) unmarshalWith(outer Registry, name string, data []byte, setup SetupFunc) (interface{}, error) {
+	return z.TypeRegistry.unmarshalWith(outer, name, data, setup)
+}
+
+func (z *ZeroRegistry) has(name string) bool {
+	return z.TypeRegistry.has(name)
+}
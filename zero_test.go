@@ -0,0 +1,49 @@
+package typeregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+type zeroType struct {
+	JSON
+	Name  string `json:"name"`
+	Count int
+	Tags  []string `json:"tags,omitempty"`
+	nameType
+}
+
+func TestWithZeroPolicy(t *testing.T) {
+	o := &zeroType{Count: 2}
+	tests := []struct {
+		policy ZeroPolicy
+		o      interface{}
+		want   string
+	}{
+		{policy: ZeroInclude, o: o, want: `{"name":"","Count":2,"Name":""}`},
+		{policy: ZeroOmit, o: o, want: `{"Count":2,"Name":""}`},
+		{policy: ZeroNull, o: o, want: `{"name":null,"Count":2,"Name":""}`},
+		{policy: ZeroOmit, o: marshalType{}, want: "bin:"},
+	}
+	for i, test := range tests {
+		r := WithZeroPolicy(New(), test.policy)
+		name := r.Add(test.o)
+		_, data, err := r.Marshal(test.o)
+		if err != nil {
+			t.Errorf("%d Marshal() wants no error, got: %s", i, err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%d Marshal() got %s, want %s", i, data, test.want)
+		}
+		got, err := r.Unmarshal(name, data, NoSetup)
+		if err != nil {
+			t.Errorf("%d Unmarshal() wants no error, got: %s", i, err)
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(test.o) {
+			t.Errorf("%d Unmarshal() got %T, want %T", i, got, test.o)
+		}
+		if test.o == o && !reflect.DeepEqual(got, o) {
+			t.Errorf("%d Unmarshal() got %#v, want %#v", i, got, o)
+		}
+	}
+}